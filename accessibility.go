@@ -0,0 +1,149 @@
+package pixelnebula
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+	"github.com/landaiqing/go-pixelnebula/style"
+)
+
+// partOrder 头像各部分的绘制顺序
+var partOrder = []style.ShapeType{
+	style.TypeEnv,
+	style.TypeHead,
+	style.TypeClo,
+	style.TypeTop,
+	style.TypeEyes,
+	style.TypeMouth,
+}
+
+// SetAccessible 设置是否输出无障碍信息（title、desc、role="img" 和 aria-label）
+// 未设置标题和描述时使用默认值，内联到HTML中时可以通过WCAG检查
+func (sb *SVGBuilder) SetAccessible(enabled bool) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	sb.accessible = enabled
+	return sb
+}
+
+// SetTitle 设置无障碍标题，同时作为 aria-label 输出
+// 为空时使用 "Avatar for <id>"；设置了命名空间时使用 "Avatar"，避免由头像得到ID
+func (sb *SVGBuilder) SetTitle(title string) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	sb.accessible = true
+	sb.title = title
+	return sb
+}
+
+// SetDescription 设置无障碍描述，为空时根据所选风格和主题生成
+func (sb *SVGBuilder) SetDescription(desc string) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	sb.accessible = true
+	sb.desc = desc
+	return sb
+}
+
+// addAccessibility 在SVG根元素上添加 role 和 aria-label，并插入 title 和 desc 元素
+func (sb *SVGBuilder) addAccessibility(svg string) (string, error) {
+	end := strings.Index(svg, ">")
+	if !strings.HasPrefix(svg, "<svg") || end == -1 {
+		return "", errors.ErrInvalidSVG
+	}
+
+	title, desc, err := sb.accessibleText()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.Grow(len(svg) + len(title)*2 + len(desc) + 64)
+	root := strings.TrimSuffix(svg[:end], "/")
+	b.WriteString(root)
+	b.WriteString(` role="img" aria-label="`)
+	attrEscaper.WriteString(&b, title)
+	b.WriteString(`"><title>`)
+	textEscaper.WriteString(&b, title)
+	b.WriteString("</title><desc>")
+	textEscaper.WriteString(&b, desc)
+	b.WriteString("</desc>")
+	b.WriteString(svg[end+1:])
+	return b.String(), nil
+}
+
+// accessibleText 获取实际输出的无障碍标题和描述，未设置时使用默认值
+func (sb *SVGBuilder) accessibleText() (title, desc string, err error) {
+	title = sb.title
+	if title == "" {
+		title = "Avatar for " + sb.id
+		if sb.pn.Namespaced {
+			title = "Avatar"
+		}
+	}
+	desc = sb.desc
+	if desc == "" {
+		if desc, err = sb.defaultDescription(); err != nil {
+			return "", "", err
+		}
+	}
+	return title, desc, nil
+}
+
+// defaultDescription 根据各部分使用的风格和主题生成描述
+func (sb *SVGBuilder) defaultDescription() (string, error) {
+	keys, err := sb.pn.partKeys(sb.id, sb.options())
+	if err != nil {
+		return "", err
+	}
+
+	var styles []string
+	sameTheme := true
+	for i, part := range partOrder {
+		key := keys[part]
+		if i > 0 && key != keys[partOrder[0]] {
+			sameTheme = false
+		}
+		name := sb.pn.styleName(key[0])
+		if !containsString(styles, name) {
+			styles = append(styles, name)
+		}
+	}
+
+	var desc string
+	if len(styles) == 1 {
+		desc = fmt.Sprintf("Avatar in the %s style", styles[0])
+		if sameTheme {
+			desc += fmt.Sprintf(" with theme %d", keys[style.TypeEnv][1])
+		}
+	} else {
+		desc = fmt.Sprintf("Avatar combining the %s and %s styles",
+			strings.Join(styles[:len(styles)-1], ", "), styles[len(styles)-1])
+	}
+	if sb.sansEnv || sb.background == BackgroundTransparent {
+		desc += ", without background"
+	}
+	return desc, nil
+}
+
+// styleName 获取风格名称，自定义风格使用索引
+func (pn *PixelNebula) styleName(index int) string {
+	if name, err := pn.StyleManager.GetStyleName(index); err == nil {
+		return string(name)
+	}
+	return fmt.Sprintf("custom %d", index)
+}
+
+// containsString 判断切片中是否包含字符串
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
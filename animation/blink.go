@@ -38,21 +38,7 @@ func (a *BlinkAnimation) GenerateSVG() string {
 	sb.WriteString(fmt.Sprintf("<animate href=\"#%s\" attributeName=\"opacity\" ", a.TargetID))
 
 	// 根据闪烁次数生成关键帧
-	var keyTimes, values []string
-
-	// 计算关键帧
-	for i := 0; i <= a.BlinkCount*2; i++ {
-		// 计算关键帧时间点
-		keyTime := float64(i) / float64(a.BlinkCount*2)
-		keyTimes = append(keyTimes, fmt.Sprintf("%.2f", keyTime))
-
-		// 计算关键帧值，交替使用最大和最小透明度
-		if i%2 == 0 {
-			values = append(values, fmt.Sprintf("%.1f", a.MaxOpacity))
-		} else {
-			values = append(values, fmt.Sprintf("%.1f", a.MinOpacity))
-		}
-	}
+	keyTimes, values := a.keyframes()
 
 	// 添加关键帧属性
 	sb.WriteString(fmt.Sprintf("keyTimes=\"%s\" ", strings.Join(keyTimes, ";")))
@@ -73,3 +59,50 @@ func (a *BlinkAnimation) GenerateSVG() string {
 
 	return sb.String()
 }
+
+// keyframes 根据闪烁次数计算关键帧时间点和对应的透明度
+func (a *BlinkAnimation) keyframes() (keyTimes, values []string) {
+	for i := 0; i <= a.BlinkCount*2; i++ {
+		// 计算关键帧时间点
+		keyTime := float64(i) / float64(a.BlinkCount*2)
+		keyTimes = append(keyTimes, fmt.Sprintf("%.2f", keyTime))
+
+		// 计算关键帧值，交替使用最大和最小透明度
+		if i%2 == 0 {
+			values = append(values, fmt.Sprintf("%.1f", a.MaxOpacity))
+		} else {
+			values = append(values, fmt.Sprintf("%.1f", a.MinOpacity))
+		}
+	}
+	return keyTimes, values
+}
+
+// Sample 计算闪烁动画在时刻t的透明度
+func (a *BlinkAnimation) Sample(t float64) (Effect, bool) {
+	p, ok := a.progress(t, false)
+	if !ok || a.BlinkCount <= 0 {
+		return Effect{}, false
+	}
+	keyTimes, values := a.keyframes()
+	return Effect{
+		TargetID:   a.TargetID,
+		Attributes: map[string]string{"opacity": keyframeValue(p, parseKeyTimes(keyTimes), values, nil)},
+	}, true
+}
+
+// GenerateCSS 生成闪烁动画的CSS关键帧
+func (a *BlinkAnimation) GenerateCSS(name string) CSSRule {
+	if a.BlinkCount <= 0 {
+		return CSSRule{}
+	}
+	keyTimes, values := a.keyframes()
+	offsets := parseKeyTimes(keyTimes)
+	frames := make([]cssKeyframe, len(values))
+	for i, v := range values {
+		frames[i] = cssKeyframe{offset: offsets[i], decl: "opacity: " + v}
+	}
+	return CSSRule{
+		Keyframes: writeKeyframes(name, frames),
+		Animation: a.cssAnimation(name, "linear", false),
+	}
+}
@@ -5,6 +5,12 @@ import (
 	"strings"
 )
 
+// 弹跳动画使用的缓动曲线
+const (
+	bounceRiseSpline = "0.2 0 0.8 1"   // 快速上升
+	bounceFallSpline = "0.2 0.8 0.8 1" // 缓慢下落
+)
+
 // BounceAnimation 弹跳动画
 type BounceAnimation struct {
 	BaseAnimation
@@ -48,24 +54,7 @@ func (a *BounceAnimation) GenerateSVG() string {
 	sb.WriteString(fmt.Sprintf("dur=\"%gs\" ", a.Duration))
 
 	// 生成弹跳效果的关键帧
-	var keyTimes, values []string
-	step := 1.0 / float64(a.BounceCount*2)
-
-	for i := 0; i <= a.BounceCount*2; i++ {
-		// 调整关键帧时间，使动画更加平滑
-		keyTime := float64(i) * step
-		// 为每个弹跳周期添加额外的中间帧
-		if i > 0 && i < a.BounceCount*2 {
-			keyTime = keyTime + (step * 0.1) // 稍微延长每次弹跳的时间
-		}
-		keyTimes = append(keyTimes, fmt.Sprintf("%.3f", keyTime))
-
-		if i%2 == 0 {
-			values = append(values, a.From)
-		} else {
-			values = append(values, a.To)
-		}
-	}
+	keyTimes, values := a.keyframes()
 
 	// 添加关键帧属性
 	sb.WriteString(fmt.Sprintf("values=\"%s\" ", strings.Join(values, ";")))
@@ -80,10 +69,10 @@ func (a *BounceAnimation) GenerateSVG() string {
 		}
 		if i%2 == 0 {
 			// 快速上升
-			sb.WriteString("0.2 0 0.8 1")
+			sb.WriteString(bounceRiseSpline)
 		} else {
 			// 缓慢下落
-			sb.WriteString("0.2 0.8 0.8 1")
+			sb.WriteString(bounceFallSpline)
 		}
 	}
 	sb.WriteString("\" ")
@@ -103,3 +92,78 @@ func (a *BounceAnimation) GenerateSVG() string {
 
 	return sb.String()
 }
+
+// keyframes 计算弹跳效果的关键帧时间点和对应的值
+func (a *BounceAnimation) keyframes() (keyTimes, values []string) {
+	step := 1.0 / float64(a.BounceCount*2)
+
+	for i := 0; i <= a.BounceCount*2; i++ {
+		// 调整关键帧时间，使动画更加平滑
+		keyTime := float64(i) * step
+		// 为每个弹跳周期添加额外的中间帧
+		if i > 0 && i < a.BounceCount*2 {
+			keyTime = keyTime + (step * 0.1) // 稍微延长每次弹跳的时间
+		}
+		keyTimes = append(keyTimes, fmt.Sprintf("%.3f", keyTime))
+
+		if i%2 == 0 {
+			values = append(values, a.From)
+		} else {
+			values = append(values, a.To)
+		}
+	}
+	return keyTimes, values
+}
+
+// Sample 计算弹跳动画在时刻t的位移或属性值，动画结束后保持最后一帧
+func (a *BounceAnimation) Sample(t float64) (Effect, bool) {
+	p, ok := a.progress(t, true)
+	if !ok || a.BounceCount <= 0 {
+		return Effect{}, false
+	}
+	keyTimes, values := a.keyframes()
+	splines := make([][4]float64, len(values)-1)
+	for i := range splines {
+		spline := bounceRiseSpline
+		if i%2 == 1 {
+			spline = bounceFallSpline
+		}
+		nums, _ := parseNumberList(spline)
+		copy(splines[i][:], nums)
+	}
+	value := keyframeValue(p, parseKeyTimes(keyTimes), values, splines)
+
+	if a.Property == "transform" {
+		return Effect{TargetID: a.TargetID, Transform: "translate(" + value + ")"}, true
+	}
+	return Effect{TargetID: a.TargetID, Attributes: map[string]string{a.Property: value}}, true
+}
+
+// GenerateCSS 生成弹跳动画的CSS关键帧，动画结束后保持最后一帧
+func (a *BounceAnimation) GenerateCSS(name string) CSSRule {
+	if a.BounceCount <= 0 {
+		return CSSRule{}
+	}
+	keyTimes, values := a.keyframes()
+	offsets := parseKeyTimes(keyTimes)
+	frames := make([]cssKeyframe, len(values))
+	for i, v := range values {
+		if a.Property == "transform" {
+			frames[i].decl = "translate: " + cssTranslate(v)
+		} else {
+			frames[i].decl = a.Property + ": " + cssPropertyValue(a.Property, v)
+		}
+		frames[i].offset = offsets[i]
+		if i < len(values)-1 {
+			spline := bounceRiseSpline
+			if i%2 == 1 {
+				spline = bounceFallSpline
+			}
+			frames[i].timing = "cubic-bezier(" + strings.ReplaceAll(spline, " ", ", ") + ")"
+		}
+	}
+	return CSSRule{
+		Keyframes: writeKeyframes(name, frames),
+		Animation: a.cssAnimation(name, "linear", true),
+	}
+}
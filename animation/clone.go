@@ -0,0 +1,94 @@
+package animation
+
+import "reflect"
+
+// Cloner 可以深拷贝自身的自定义动画，Clone 和 Manager.Clone 会调用它复制自定义动画
+type Cloner interface {
+	// Clone 返回与原动画不共享任何可变状态的副本
+	Clone() Animation
+}
+
+// Clone 深拷贝内置动画，包括属性和替代动画；实现了 Cloner 的自定义动画调用其 Clone，其余自定义动画原样返回
+func Clone(anim Animation) Animation {
+	switch a := anim.(type) {
+	case *RotateAnimation:
+		c := *a
+		c.BaseAnimation = a.BaseAnimation.clone()
+		return &c
+	case *GradientAnimation:
+		c := *a
+		c.BaseAnimation = a.BaseAnimation.clone()
+		c.Colors = append([]string(nil), a.Colors...)
+		return &c
+	case *TransformAnimation:
+		c := *a
+		c.BaseAnimation = a.BaseAnimation.clone()
+		return &c
+	case *FadeAnimation:
+		c := *a
+		c.BaseAnimation = a.BaseAnimation.clone()
+		return &c
+	case *PathAnimation:
+		c := *a
+		c.BaseAnimation = a.BaseAnimation.clone()
+		return &c
+	case *ColorAnimation:
+		c := *a
+		c.BaseAnimation = a.BaseAnimation.clone()
+		return &c
+	case *BounceAnimation:
+		c := *a
+		c.BaseAnimation = a.BaseAnimation.clone()
+		return &c
+	case *WaveAnimation:
+		c := *a
+		c.BaseAnimation = a.BaseAnimation.clone()
+		return &c
+	case *BlinkAnimation:
+		c := *a
+		c.BaseAnimation = a.BaseAnimation.clone()
+		return &c
+	case Cloner:
+		return a.Clone()
+	}
+	return anim
+}
+
+// Cloneable 判断 Clone 能否完整深拷贝动画
+// 内置动画及其替代动画都能深拷贝时返回 true，自定义动画需要实现 Cloner
+func Cloneable(anim Animation) bool {
+	switch a := anim.(type) {
+	case *RotateAnimation, *GradientAnimation, *TransformAnimation, *FadeAnimation, *PathAnimation,
+		*ColorAnimation, *BounceAnimation, *WaveAnimation, *BlinkAnimation:
+		if reduced := a.(ReducedMotionVariant).GetReduced(); reduced != nil {
+			return Cloneable(reduced)
+		}
+		return true
+	case Cloner:
+		return true
+	}
+	return false
+}
+
+// clone 复制基础属性
+func (a *BaseAnimation) clone() BaseAnimation {
+	c := *a
+	if a.Attributes != nil {
+		c.Attributes = make(map[string]string, len(a.Attributes))
+		for k, v := range a.Attributes {
+			c.Attributes[k] = v
+		}
+	}
+	if a.Reduced != nil {
+		c.Reduced = Clone(a.Reduced)
+	}
+	return c
+}
+
+// sameAnimation 判断是否为同一个动画，无法比较的类型视为不同
+func sameAnimation(a, b Animation) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return a == nil && b == nil
+	}
+	return a == b
+}
@@ -53,3 +53,26 @@ func (a *ColorAnimation) GenerateSVG() string {
 
 	return sb.String()
 }
+
+// Sample 计算颜色变换动画在时刻t的颜色
+func (a *ColorAnimation) Sample(t float64) (Effect, bool) {
+	p, ok := a.progress(t, false)
+	if !ok {
+		return Effect{}, false
+	}
+	return Effect{
+		TargetID:   a.TargetID,
+		Attributes: map[string]string{a.Property: interpolateValue(a.FromColor, a.ToColor, p)},
+	}, true
+}
+
+// GenerateCSS 生成颜色变换动画的CSS关键帧
+func (a *ColorAnimation) GenerateCSS(name string) CSSRule {
+	return CSSRule{
+		Keyframes: writeKeyframes(name, []cssKeyframe{
+			{offset: 0, decl: a.Property + ": " + a.FromColor},
+			{offset: 1, decl: a.Property + ": " + a.ToColor},
+		}),
+		Animation: a.cssAnimation(name, "linear", false),
+	}
+}
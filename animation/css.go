@@ -0,0 +1,302 @@
+package animation
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Mode 动画输出模式
+type Mode string
+
+// 预定义动画输出模式常量
+const (
+	ModeSMIL Mode = "smil" // 输出SMIL动画元素（默认）
+	ModeCSS  Mode = "css"  // 输出 @keyframes 规则和CSS animation声明
+)
+
+// CSSRule 单个动画对应的CSS输出
+type CSSRule struct {
+	Keyframes  string   // @keyframes 规则
+	Animation  string   // animation 简写值，如 "pn-rotate-env-0 10s linear infinite"
+	Properties []string // 目标元素需要的附加声明，如 "transform-box: fill-box"
+}
+
+// CSSAnimation 可以输出为CSS关键帧动画的动画
+// 除渐变动画外的内置动画均实现了该接口
+type CSSAnimation interface {
+	Animation
+	// GenerateCSS 以name作为关键帧名称生成CSS规则
+	GenerateCSS(name string) CSSRule
+}
+
+// keyframeName 生成稳定的关键帧名称
+func keyframeName(prefix string, anim Animation, index int) string {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteByte('-')
+	sb.WriteString(string(anim.GetType()))
+	sb.WriteByte('-')
+	for _, r := range anim.GetTargetID() {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('-')
+		}
+	}
+	sb.WriteString(fmt.Sprintf("-%d", index))
+	return sb.String()
+}
+
+// cssAnimation 生成 animation 简写值
+func (a *BaseAnimation) cssAnimation(name, timing string, forwards bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %gs %s", name, a.Duration, timing))
+	if a.Delay > 0 {
+		sb.WriteString(fmt.Sprintf(" %gs", a.Delay))
+	}
+	if a.RepeatCount < 0 {
+		sb.WriteString(" infinite")
+	} else if a.RepeatCount > 1 {
+		sb.WriteString(fmt.Sprintf(" %d", a.RepeatCount))
+	}
+	if forwards {
+		sb.WriteString(" forwards")
+	}
+	return sb.String()
+}
+
+// cssKeyframe 表示一个关键帧
+type cssKeyframe struct {
+	offset float64 // 0-1
+	decl   string  // 关键帧内的声明
+	timing string  // 关键帧到下一帧使用的缓动函数，空表示使用默认值
+}
+
+// writeKeyframes 生成 @keyframes 规则
+func writeKeyframes(name string, frames []cssKeyframe) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("@keyframes %s {\n", name))
+	for _, f := range frames {
+		sb.WriteString(fmt.Sprintf("  %s%% { %s;", formatNumber(f.offset*100), f.decl))
+		if f.timing != "" {
+			sb.WriteString(fmt.Sprintf(" animation-timing-function: %s;", f.timing))
+		}
+		sb.WriteString(" }\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// cssTranslate 将SMIL的 translate 值（"tx" 或 "tx,ty"）转换为CSS translate属性值
+func cssTranslate(v string) string {
+	nums, ok := parseNumberList(v)
+	if !ok {
+		return "0px 0px"
+	}
+	ty := 0.0
+	if len(nums) > 1 {
+		ty = nums[1]
+	}
+	return formatNumber(nums[0]) + "px " + formatNumber(ty) + "px"
+}
+
+// cssScale 将SMIL的 scale 值（"s" 或 "sx,sy"）转换为CSS scale属性值
+func cssScale(v string) string {
+	nums, ok := parseNumberList(v)
+	if !ok {
+		return "1"
+	}
+	if len(nums) > 1 {
+		return formatNumber(nums[0]) + " " + formatNumber(nums[1])
+	}
+	return formatNumber(nums[0])
+}
+
+// cssAngle 取SMIL的 rotate/skew 值中的角度
+func cssAngle(v string) string {
+	nums, ok := parseNumberList(v)
+	if !ok {
+		return "0deg"
+	}
+	return formatNumber(nums[0]) + "deg"
+}
+
+// cssLength SVG几何属性需要带单位
+var cssLengthProperties = map[string]bool{
+	"x": true, "y": true, "cx": true, "cy": true, "r": true,
+	"rx": true, "ry": true, "width": true, "height": true,
+	"stroke-width": true,
+}
+
+// cssPropertyValue 将SMIL的属性值转换为CSS属性值
+func cssPropertyValue(property, v string) string {
+	if cssLengthProperties[property] {
+		if nums, ok := parseNumberList(v); ok && len(nums) == 1 {
+			return formatNumber(nums[0]) + "px"
+		}
+	}
+	return v
+}
+
+// pacedKeyframes 按路径长度为折线上的点分配关键帧时间，与SMIL animateMotion 的 paced 计时一致
+func pacedKeyframes(points [][2]float64) []cssKeyframe {
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += math.Hypot(points[i][0]-points[i-1][0], points[i][1]-points[i-1][1])
+	}
+
+	frames := make([]cssKeyframe, 0, len(points))
+	walked := 0.0
+	for i, p := range points {
+		if i > 0 {
+			walked += math.Hypot(p[0]-points[i-1][0], p[1]-points[i-1][1])
+		}
+		offset := 0.0
+		if total > 0 {
+			offset = walked / total
+		} else if len(points) > 1 {
+			offset = float64(i) / float64(len(points)-1)
+		}
+		frames = append(frames, cssKeyframe{
+			offset: offset,
+			decl:   "translate: " + formatNumber(p[0]) + "px " + formatNumber(p[1]) + "px",
+		})
+	}
+	return frames
+}
+
+// cssRuleSet 按目标元素合并的CSS动画声明
+type cssRuleSet struct {
+	targets    []string
+	animations map[string][]string
+	properties map[string][]string
+}
+
+// newCSSRuleSet 创建一个空的规则集合
+func newCSSRuleSet() *cssRuleSet {
+	return &cssRuleSet{
+		animations: make(map[string][]string),
+		properties: make(map[string][]string),
+	}
+}
+
+// add 将动画规则合并到目标元素的声明中
+func (rs *cssRuleSet) add(target string, rule CSSRule) {
+	if _, ok := rs.animations[target]; !ok {
+		rs.targets = append(rs.targets, target)
+	}
+	rs.animations[target] = append(rs.animations[target], rule.Animation)
+	for _, p := range rule.Properties {
+		if !containsString(rs.properties[target], p) {
+			rs.properties[target] = append(rs.properties[target], p)
+		}
+	}
+}
+
+// write 输出每个目标元素的声明
+func (rs *cssRuleSet) write(sb *strings.Builder, indent string) {
+	for _, target := range rs.targets {
+		sb.WriteString(fmt.Sprintf("%s#%s { animation: %s;", indent, target, strings.Join(rs.animations[target], ", ")))
+		for _, p := range rs.properties[target] {
+			sb.WriteString(" " + p + ";")
+		}
+		sb.WriteString(" }\n")
+	}
+}
+
+// GenerateCSSAnimations 生成CSS动画代码
+// 每个动画输出一条 @keyframes 规则，同一目标的动画合并为一条 animation 声明
+// 渐变动画和未实现 CSSAnimation 的自定义动画仍以SMIL元素输出
+// 启用减弱动态效果时，在 prefers-reduced-motion: reduce 下停用动画或替换为其减弱版本，
+// SMIL元素无法响应媒体查询，自定义动画被去掉，渐变只保留静态定义
+func (m *Manager) GenerateCSSAnimations() string {
+	if len(m.animations) == 0 {
+		return ""
+	}
+
+	sb := animationBuilderPool.Get().(*strings.Builder)
+	sb.Reset()
+	defer animationBuilderPool.Put(sb)
+
+	var (
+		defs      strings.Builder
+		keyframes strings.Builder
+		fallback  strings.Builder
+		rules     = newCSSRuleSet()
+		reduced   = newCSSRuleSet()
+	)
+
+	for i, anim := range m.animations {
+		switch a := anim.(type) {
+		case *GradientAnimation:
+			// 渐变的 x1、x2 不是CSS属性，无法用关键帧实现，以SMIL元素输出
+			if m.reducedMotion {
+				defs.WriteString(a.generateDefs())
+			} else {
+				defs.WriteString(a.GenerateSVG())
+			}
+		case CSSAnimation:
+			rule := a.GenerateCSS(keyframeName("pn", a, i))
+			if rule.Animation == "" {
+				continue
+			}
+			keyframes.WriteString(rule.Keyframes)
+			rules.add(a.GetTargetID(), rule)
+		default:
+			if !m.reducedMotion {
+				fallback.WriteString(anim.GenerateSVG())
+			}
+			continue
+		}
+
+		if !m.reducedMotion {
+			continue
+		}
+		if r, ok := anim.(ReducedMotionVariant); ok {
+			if variant, ok := r.GetReduced().(CSSAnimation); ok {
+				rule := variant.GenerateCSS(keyframeName("pn-reduced", variant, i))
+				if rule.Animation != "" {
+					keyframes.WriteString(rule.Keyframes)
+					reduced.add(variant.GetTargetID(), rule)
+				}
+			}
+		}
+	}
+
+	if defs.Len() > 0 {
+		sb.WriteString("<defs>\n")
+		sb.WriteString(defs.String())
+		sb.WriteString("</defs>\n")
+	}
+
+	if len(rules.targets) > 0 {
+		sb.WriteString("<style type=\"text/css\">\n")
+		sb.WriteString(keyframes.String())
+		rules.write(sb, "")
+		if m.reducedMotion {
+			sb.WriteString("@media (prefers-reduced-motion: reduce) {\n")
+			for _, target := range rules.targets {
+				if _, ok := reduced.animations[target]; !ok {
+					sb.WriteString(fmt.Sprintf("  #%s { animation: none; }\n", target))
+				}
+			}
+			reduced.write(sb, "  ")
+			sb.WriteString("}\n")
+		}
+		sb.WriteString("</style>\n")
+	}
+
+	sb.WriteString(fallback.String())
+	return sb.String()
+}
+
+// containsString 判断切片中是否包含字符串
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
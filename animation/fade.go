@@ -48,3 +48,26 @@ func (a *FadeAnimation) GenerateSVG() string {
 
 	return svg
 }
+
+// Sample 计算淡入淡出动画在时刻t的透明度
+func (a *FadeAnimation) Sample(t float64) (Effect, bool) {
+	p, ok := a.progress(t, false)
+	if !ok {
+		return Effect{}, false
+	}
+	return Effect{
+		TargetID:   a.TargetID,
+		Attributes: map[string]string{"opacity": interpolateValue(a.From, a.To, p)},
+	}, true
+}
+
+// GenerateCSS 生成淡入淡出动画的CSS关键帧
+func (a *FadeAnimation) GenerateCSS(name string) CSSRule {
+	return CSSRule{
+		Keyframes: writeKeyframes(name, []cssKeyframe{
+			{offset: 0, decl: "opacity: " + a.From},
+			{offset: 1, decl: "opacity: " + a.To},
+		}),
+		Animation: a.cssAnimation(name, "linear", false),
+	}
+}
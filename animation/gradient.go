@@ -31,23 +31,12 @@ func NewGradientAnimation(targetID string, colors []string, duration float64, re
 // GenerateSVG 生成渐变动画的SVG代码
 func (a *GradientAnimation) GenerateSVG() string {
 	var sb strings.Builder
-
-	// 创建渐变定义
-	gradientID := fmt.Sprintf("%s-gradient", a.TargetID)
-	sb.WriteString(fmt.Sprintf("<linearGradient id=\"%s\" x1=\"0%%\" y1=\"0%%\" x2=\"100%%\" y2=\"0%%\">\n", gradientID))
-
-	// 添加渐变颜色
-	for i, color := range a.Colors {
-		offset := float64(i) / float64(len(a.Colors)-1) * 100
-		sb.WriteString(fmt.Sprintf("  <stop offset=\"%g%%\" stop-color=\"%s\" />\n", offset, color))
-	}
-	sb.WriteString("</linearGradient>\n")
-
-	// 为目标元素添加样式引用
-	sb.WriteString(fmt.Sprintf("<style type=\"text/css\">\n  #%s { fill: url(#%s) !important; }\n</style>\n", a.TargetID, gradientID))
+	sb.WriteString(a.generateDefs())
 
 	// 添加动画
 	if a.Animate {
+		gradientID := fmt.Sprintf("%s-gradient", a.TargetID)
+
 		// x1 动画
 		sb.WriteString(fmt.Sprintf("<animate href=\"#%s\" attributeName=\"x1\" from=\"0%%\" to=\"100%%\" ", gradientID))
 		sb.WriteString(fmt.Sprintf("dur=\"%gs\" ", a.Duration))
@@ -71,3 +60,42 @@ func (a *GradientAnimation) GenerateSVG() string {
 
 	return sb.String()
 }
+
+// generateDefs 生成渐变定义及目标元素的样式引用，不包含动画
+func (a *GradientAnimation) generateDefs() string {
+	var sb strings.Builder
+
+	// 创建渐变定义
+	gradientID := fmt.Sprintf("%s-gradient", a.TargetID)
+	sb.WriteString(fmt.Sprintf("<linearGradient id=\"%s\" x1=\"0%%\" y1=\"0%%\" x2=\"100%%\" y2=\"0%%\">\n", gradientID))
+
+	// 添加渐变颜色
+	for i, color := range a.Colors {
+		offset := float64(i) / float64(len(a.Colors)-1) * 100
+		sb.WriteString(fmt.Sprintf("  <stop offset=\"%g%%\" stop-color=\"%s\" />\n", offset, color))
+	}
+	sb.WriteString("</linearGradient>\n")
+
+	// 为目标元素添加样式引用
+	sb.WriteString(fmt.Sprintf("<style type=\"text/css\">\n  #%s { fill: url(#%s) !important; }\n</style>\n", a.TargetID, gradientID))
+
+	return sb.String()
+}
+
+// Sample 计算渐变动画在时刻t的渐变位置，目标为渐变定义本身
+func (a *GradientAnimation) Sample(t float64) (Effect, bool) {
+	if !a.Animate {
+		return Effect{}, false
+	}
+	p, ok := a.progress(t, false)
+	if !ok {
+		return Effect{}, false
+	}
+	return Effect{
+		TargetID: fmt.Sprintf("%s-gradient", a.TargetID),
+		Attributes: map[string]string{
+			"x1": formatNumber(100*p) + "%",
+			"x2": formatNumber(100+100*p) + "%",
+		},
+	}, true
+}
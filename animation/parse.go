@@ -0,0 +1,220 @@
+package animation
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+// rotateWrapper 包裹旋转目标元素的g元素
+const rotateWrapper = `<g style="transform-box: fill-box; transform-origin: center;">`
+
+var (
+	// smilRegex 按文档顺序匹配渐变定义和SMIL动画元素
+	smilRegex = regexp.MustCompile(`(?s)<linearGradient id="([^"]+)-gradient"[^>]*>(.*?)</linearGradient>|<(animate|animateTransform|animateMotion)\s([^>]*?)/>`)
+	// attrRegex 匹配元素属性
+	attrRegex = regexp.MustCompile(`([\w:-]+)="([^"]*)"`)
+	// stopColorRegex 匹配渐变的颜色节点
+	stopColorRegex = regexp.MustCompile(`<stop [^>]*stop-color="([^"]*)"`)
+	// targetIDRegex 匹配旋转包裹元素中目标元素的ID
+	targetIDRegex = regexp.MustCompile(`id=['"]([^'"]+)['"]`)
+)
+
+// ParseSVG 从SVG中解析出内置动画，顺序与 Manager 输出动画代码的顺序一致
+// 只支持SMIL模式的输出；减弱动态效果的替代动画和未输出到SVG中的参数无法还原
+func ParseSVG(svg string) ([]Animation, error) {
+	if strings.Contains(svg, "@keyframes") {
+		return nil, fmt.Errorf("%w: css animations cannot be parsed", errors.ErrInvalidAnimation)
+	}
+
+	var (
+		anims     []Animation
+		rotates   []Animation
+		gradients = make(map[string]*GradientAnimation)
+	)
+	for _, m := range smilRegex.FindAllStringSubmatchIndex(svg, -1) {
+		if m[2] >= 0 {
+			target := svg[m[2]:m[3]]
+			g := &GradientAnimation{BaseAnimation: BaseAnimation{Type: Gradient, TargetID: target, Attributes: make(map[string]string)}}
+			for _, stop := range stopColorRegex.FindAllStringSubmatch(svg[m[4]:m[5]], -1) {
+				g.Colors = append(g.Colors, stop[1])
+			}
+			gradients[target+"-gradient"] = g
+			anims = append(anims, g)
+			continue
+		}
+
+		tag := svg[m[6]:m[7]]
+		attrs := make(map[string]string)
+		for _, a := range attrRegex.FindAllStringSubmatch(svg[m[8]:m[9]], -1) {
+			attrs[a[1]] = a[2]
+		}
+		base, err := parseBase(attrs)
+		if err != nil {
+			return nil, err
+		}
+
+		if g, ok := gradients[base.TargetID]; ok {
+			// 渐变的动画由 x1、x2 两个元素组成
+			g.Animate = true
+			g.Duration, g.RepeatCount = base.Duration, base.RepeatCount
+			continue
+		}
+
+		switch {
+		case tag == "animateTransform" && base.TargetID == "":
+			if attrs["type"] != "rotate" {
+				return nil, fmt.Errorf("%w: animateTransform without target", errors.ErrInvalidAnimation)
+			}
+			start := strings.LastIndex(svg[:m[0]], rotateWrapper)
+			if start < 0 {
+				return nil, fmt.Errorf("%w: rotate animation without target", errors.ErrInvalidAnimation)
+			}
+			id := targetIDRegex.FindStringSubmatch(svg[start:m[0]])
+			if id == nil {
+				return nil, fmt.Errorf("%w: rotate animation without target", errors.ErrInvalidAnimation)
+			}
+			base.Type, base.TargetID = Rotate, id[1]
+			a := &RotateAnimation{BaseAnimation: base}
+			a.FromAngle, _ = strconv.ParseFloat(attrs["from"], 64)
+			a.ToAngle, _ = strconv.ParseFloat(attrs["to"], 64)
+			rotates = append(rotates, a)
+		case attrs["calcMode"] == "spline":
+			base.Type = Bounce
+			values := strings.Split(attrs["values"], ";")
+			a := &BounceAnimation{BaseAnimation: base, Property: attrs["attributeName"], From: attrs["from"], To: attrs["to"], BounceCount: (len(values) - 1) / 2}
+			anims = append(anims, a)
+		case tag == "animateTransform":
+			base.Type = Transform
+			anims = append(anims, &TransformAnimation{BaseAnimation: base, TransformType: attrs["type"], From: attrs["from"], To: attrs["to"]})
+		case tag == "animateMotion":
+			if rotate, ok := attrs["rotate"]; ok {
+				base.Type = Path
+				anims = append(anims, &PathAnimation{BaseAnimation: base, Path: attrs["path"], Rotate: rotate})
+				continue
+			}
+			base.Type = Wave
+			a, err := parseWave(base, attrs["path"])
+			if err != nil {
+				return nil, err
+			}
+			anims = append(anims, a)
+		case attrs["attributeName"] == "opacity" && attrs["keyTimes"] != "":
+			base.Type = Blink
+			values := strings.Split(attrs["values"], ";")
+			a := &BlinkAnimation{BaseAnimation: base, BlinkCount: (len(values) - 1) / 2}
+			a.MaxOpacity, _ = strconv.ParseFloat(values[0], 64)
+			if len(values) > 1 {
+				a.MinOpacity, _ = strconv.ParseFloat(values[1], 64)
+			}
+			anims = append(anims, a)
+		case attrs["attributeName"] == "opacity":
+			base.Type = Fade
+			anims = append(anims, &FadeAnimation{BaseAnimation: base, From: attrs["from"], To: attrs["to"]})
+		default:
+			base.Type = Color
+			anims = append(anims, &ColorAnimation{BaseAnimation: base, Property: attrs["attributeName"], FromColor: attrs["from"], ToColor: attrs["to"]})
+		}
+	}
+	return append(anims, rotates...), nil
+}
+
+// parseBase 解析动画元素的通用属性
+func parseBase(attrs map[string]string) (BaseAnimation, error) {
+	base := BaseAnimation{
+		TargetID:   strings.TrimPrefix(attrs["href"], "#"),
+		Attributes: make(map[string]string),
+	}
+	var err error
+	if base.Duration, err = parseSeconds(attrs["dur"]); err != nil {
+		return base, err
+	}
+	if begin, ok := attrs["begin"]; ok {
+		if base.Delay, err = parseSeconds(begin); err != nil {
+			return base, err
+		}
+	}
+	switch repeat := attrs["repeatCount"]; repeat {
+	case "":
+	case "indefinite":
+		base.RepeatCount = -1
+	default:
+		if base.RepeatCount, err = strconv.Atoi(repeat); err != nil {
+			return base, fmt.Errorf("%w: invalid repeatCount %q", errors.ErrInvalidAnimation, repeat)
+		}
+	}
+	return base, nil
+}
+
+// parseSeconds 解析以秒为单位的时间
+func parseSeconds(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "s"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid time %q", errors.ErrInvalidAnimation, s)
+	}
+	return v, nil
+}
+
+// parseWave 由波浪路径反推振幅、频率和方向
+// 路径点为 y = A·sin(F·x°)，x 每次增加5，由前两个点解出 F 和 A，取能重新生成相同路径的解
+func parseWave(base BaseAnimation, path string) (*WaveAnimation, error) {
+	var points [][2]float64
+	for _, f := range strings.Fields(path) {
+		xy := strings.Split(strings.TrimLeft(f, "ML"), ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("%w: invalid wave path", errors.ErrInvalidAnimation)
+		}
+		x, err1 := strconv.ParseFloat(xy[0], 64)
+		y, err2 := strconv.ParseFloat(xy[1], 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("%w: invalid wave path", errors.ErrInvalidAnimation)
+		}
+		points = append(points, [2]float64{x, y})
+	}
+	// 第一个点是 M0,0，随后是 x 为 0、5、10 的路径点
+	if len(points) < 4 {
+		return nil, fmt.Errorf("%w: invalid wave path", errors.ErrInvalidAnimation)
+	}
+
+	a := &WaveAnimation{BaseAnimation: base, Direction: "horizontal"}
+	y1, y2 := points[2][1], points[3][1]
+	if points[2][0] != 5 {
+		a.Direction = "vertical"
+		y1, y2 = points[2][0], points[3][0]
+	}
+	if y1 == 0 {
+		return a, nil
+	}
+
+	c := math.Max(-1, math.Min(1, y2/(2*y1)))
+	base5 := math.Acos(c) * 180 / math.Pi
+	// sin(5F°) 以72为周期，依次尝试 F、72-F、72+F ... 并取整到有效数字
+	for k := 0; k < 8; k++ {
+		for _, f := range []float64{float64(k)*72 + base5, float64(k+1)*72 - base5} {
+			f /= 5
+			for _, freq := range []float64{roundSignificant(f), f} {
+				if s := math.Sin(freq * 5 * math.Pi / 180); s != 0 {
+					for _, amp := range []float64{roundSignificant(y1 / s), y1 / s} {
+						a.Frequency, a.Amplitude = freq, amp
+						if a.generateWavePath() == path {
+							return a, nil
+						}
+					}
+				}
+			}
+		}
+	}
+	a.Frequency = base5 / 5
+	a.Amplitude = y1 / math.Sin(base5*math.Pi/180)
+	return a, nil
+}
+
+// roundSignificant 保留9位有效数字，消除反三角函数带来的误差
+func roundSignificant(v float64) float64 {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 9, 64), 64)
+	return f
+}
@@ -60,3 +60,38 @@ func (a *PathAnimation) GenerateSVG() string {
 
 	return sb.String()
 }
+
+// Sample 计算路径动画在时刻t的位置
+func (a *PathAnimation) Sample(t float64) (Effect, bool) {
+	p, ok := a.progress(t, false)
+	if !ok {
+		return Effect{}, false
+	}
+	return Effect{
+		TargetID: a.TargetID,
+		Motion:   &Motion{Path: a.Path, Progress: p, Rotate: a.Rotate},
+	}, true
+}
+
+// GenerateCSS 生成路径动画的CSS关键帧，使用 offset-path 沿路径移动元素
+func (a *PathAnimation) GenerateCSS(name string) CSSRule {
+	rotate := "0deg"
+	switch a.Rotate {
+	case "auto":
+		rotate = "auto"
+	case "auto-reverse":
+		rotate = "auto 180deg"
+	default:
+		if nums, ok := parseNumberList(a.Rotate); ok {
+			rotate = formatNumber(nums[0]) + "deg"
+		}
+	}
+	return CSSRule{
+		Keyframes: writeKeyframes(name, []cssKeyframe{
+			{offset: 0, decl: "offset-distance: 0%"},
+			{offset: 1, decl: "offset-distance: 100%"},
+		}),
+		Animation:  a.cssAnimation(name, "linear", false),
+		Properties: []string{"offset-path: path('" + a.Path + "')", "offset-rotate: " + rotate, "offset-anchor: 0 0"},
+	}
+}
@@ -59,3 +59,28 @@ func (a *RotateAnimation) GenerateSVG() string {
 
 	return svg
 }
+
+// Sample 计算旋转动画在时刻t的角度
+func (a *RotateAnimation) Sample(t float64) (Effect, bool) {
+	p, ok := a.progress(t, false)
+	if !ok {
+		return Effect{}, false
+	}
+	return Effect{
+		TargetID:  a.TargetID,
+		Rotate:    a.FromAngle + (a.ToAngle-a.FromAngle)*p,
+		HasRotate: true,
+	}, true
+}
+
+// GenerateCSS 生成旋转动画的CSS关键帧，围绕目标自身的包围盒中心旋转
+func (a *RotateAnimation) GenerateCSS(name string) CSSRule {
+	return CSSRule{
+		Keyframes: writeKeyframes(name, []cssKeyframe{
+			{offset: 0, decl: "rotate: " + formatNumber(a.FromAngle) + "deg"},
+			{offset: 1, decl: "rotate: " + formatNumber(a.ToAngle) + "deg"},
+		}),
+		Animation:  a.cssAnimation(name, "linear", false),
+		Properties: []string{"transform-box: fill-box", "transform-origin: center"},
+	}
+}
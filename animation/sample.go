@@ -0,0 +1,238 @@
+package animation
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Motion 描述路径动画在某一时刻的位置
+type Motion struct {
+	Path     string  // SVG路径数据
+	Progress float64 // 沿路径的进度（0-1，按路径长度计算）
+	Rotate   string  // 旋转方式 ("auto", "auto-reverse" 或角度值)
+}
+
+// Effect 描述动画在某一时刻对目标元素产生的静态效果
+type Effect struct {
+	TargetID   string            // 目标元素ID
+	Rotate     float64           // 绕目标包围盒中心旋转的角度（度）
+	HasRotate  bool              // 是否包含中心旋转
+	Transform  string            // 施加到目标元素的变换，如 "translate(0,-5)"
+	Additive   bool              // 变换是否叠加在元素原有变换之后，否则替换原有变换
+	Motion     *Motion           // 路径动画的位置，nil表示无
+	Attributes map[string]string // 被覆盖的属性值，如 opacity、fill
+}
+
+// Sampler 可以在任意时刻求值的动画，内置动画均实现了该接口
+type Sampler interface {
+	Animation
+	// Sample 计算动画在时刻t（秒）的效果，动画未生效时返回false
+	Sample(t float64) (Effect, bool)
+	// Period 返回动画完成一次迭代所需的时间（含延迟）
+	Period() float64
+}
+
+// Period 返回动画完成一次迭代所需的时间（含延迟）
+func (a *BaseAnimation) Period() float64 {
+	return a.Delay + a.Duration
+}
+
+// progress 计算时刻t在当前迭代中的进度（0-1）
+// 动画尚未开始，或已结束且未设置freeze时返回false
+func (a *BaseAnimation) progress(t float64, freeze bool) (float64, bool) {
+	if a.Duration <= 0 {
+		return 0, false
+	}
+	local := t - a.Delay
+	if local < 0 {
+		return 0, false
+	}
+
+	// 未设置repeatCount时只播放一次
+	if a.RepeatCount >= 0 {
+		count := a.RepeatCount
+		if count == 0 {
+			count = 1
+		}
+		if local >= float64(count)*a.Duration {
+			if freeze {
+				return 1, true
+			}
+			return 0, false
+		}
+	}
+
+	return math.Mod(local, a.Duration) / a.Duration, true
+}
+
+// TimelineDuration 返回一组动画中最长的一次迭代时间，用于确定导出帧的时间轴长度
+func TimelineDuration(anims []Animation) float64 {
+	longest := 0.0
+	for _, anim := range anims {
+		if s, ok := anim.(Sampler); ok {
+			longest = math.Max(longest, s.Period())
+		}
+	}
+	return longest
+}
+
+// SampleAll 计算一组动画在时刻t的全部效果，未实现Sampler的动画会被忽略
+func SampleAll(anims []Animation, t float64) []Effect {
+	effects := make([]Effect, 0, len(anims))
+	for _, anim := range anims {
+		s, ok := anim.(Sampler)
+		if !ok {
+			continue
+		}
+		if e, ok := s.Sample(t); ok {
+			effects = append(effects, e)
+		}
+	}
+	return effects
+}
+
+// keyframeValue 按关键帧求值，keySplines 为空时使用线性插值
+func keyframeValue(p float64, keyTimes []float64, values []string, keySplines [][4]float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	if len(values) == 1 || p <= keyTimes[0] {
+		return values[0]
+	}
+	for i := 1; i < len(keyTimes); i++ {
+		if p > keyTimes[i] {
+			continue
+		}
+		span := keyTimes[i] - keyTimes[i-1]
+		f := 1.0
+		if span > 0 {
+			f = (p - keyTimes[i-1]) / span
+		}
+		if i-1 < len(keySplines) {
+			f = cubicBezierEase(keySplines[i-1], f)
+		}
+		return interpolateValue(values[i-1], values[i], f)
+	}
+	return values[len(values)-1]
+}
+
+// cubicBezierEase 求解 keySplines 定义的缓动曲线
+func cubicBezierEase(s [4]float64, x float64) float64 {
+	bezier := func(t, p1, p2 float64) float64 {
+		mt := 1 - t
+		return 3*mt*mt*t*p1 + 3*mt*t*t*p2 + t*t*t
+	}
+	lo, hi := 0.0, 1.0
+	t := x
+	for i := 0; i < 32; i++ {
+		bx := bezier(t, s[0], s[2])
+		if math.Abs(bx-x) < 1e-6 {
+			break
+		}
+		if bx < x {
+			lo = t
+		} else {
+			hi = t
+		}
+		t = (lo + hi) / 2
+	}
+	return bezier(t, s[1], s[3])
+}
+
+// interpolateValue 在两个属性值之间插值，支持颜色和数字列表，无法插值时在中点切换
+func interpolateValue(from, to string, f float64) string {
+	if fc, ok := parseHexColor(from); ok {
+		if tc, ok := parseHexColor(to); ok {
+			var out [3]float64
+			for i := range out {
+				out[i] = fc[i] + (tc[i]-fc[i])*f
+			}
+			return formatHexColor(out)
+		}
+	}
+
+	fromNums, fromOK := parseNumberList(from)
+	toNums, toOK := parseNumberList(to)
+	if fromOK && toOK && len(fromNums) == len(toNums) {
+		parts := make([]string, len(fromNums))
+		for i := range fromNums {
+			parts[i] = formatNumber(fromNums[i] + (toNums[i]-fromNums[i])*f)
+		}
+		return strings.Join(parts, ",")
+	}
+
+	if f < 0.5 {
+		return from
+	}
+	return to
+}
+
+// parseNumberList 解析以逗号或空格分隔的数字列表，带单位或百分比的值视为无法解析
+func parseNumberList(s string) ([]float64, bool) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, false
+	}
+	nums := make([]float64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(f, "%"), 64)
+		if err != nil || strings.HasSuffix(f, "%") {
+			return nil, false
+		}
+		nums[i] = v
+	}
+	return nums, true
+}
+
+// parseHexColor 解析 #rgb 或 #rrggbb 颜色
+func parseHexColor(s string) ([3]float64, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "#") {
+		return [3]float64{}, false
+	}
+	hex := s[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return [3]float64{}, false
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return [3]float64{}, false
+	}
+	return [3]float64{float64(n >> 16 & 0xff), float64(n >> 8 & 0xff), float64(n & 0xff)}, true
+}
+
+// formatHexColor 将RGB分量格式化为 #rrggbb
+func formatHexColor(c [3]float64) string {
+	var sb strings.Builder
+	sb.WriteByte('#')
+	for _, v := range c {
+		n := int(math.Round(math.Max(0, math.Min(255, v))))
+		if n < 16 {
+			sb.WriteByte('0')
+		}
+		sb.WriteString(strconv.FormatInt(int64(n), 16))
+	}
+	return sb.String()
+}
+
+// formatNumber 格式化数字，保留4位小数以保证输出稳定
+func formatNumber(f float64) string {
+	f = math.Round(f*1e4) / 1e4
+	if f == 0 {
+		f = 0 // 避免输出 -0
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// parseKeyTimes 解析关键帧时间点
+func parseKeyTimes(keyTimes []string) []float64 {
+	out := make([]float64, len(keyTimes))
+	for i, kt := range keyTimes {
+		out[i], _ = strconv.ParseFloat(kt, 64)
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package animation
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+// Spec 动画的可序列化描述，可以用 json 保存后再用 FromSpec 还原
+type Spec struct {
+	Type    AnimationType   `json:"type"`              // 动画类型
+	Params  json.RawMessage `json:"params"`            // 动画参数
+	Reduced *Spec           `json:"reduced,omitempty"` // 减弱动态效果时使用的替代动画
+}
+
+// newAnimation 创建指定类型的空动画，用于反序列化
+func newAnimation(t AnimationType) (Animation, bool) {
+	switch t {
+	case Rotate:
+		return &RotateAnimation{}, true
+	case Gradient:
+		return &GradientAnimation{}, true
+	case Transform:
+		return &TransformAnimation{}, true
+	case Fade:
+		return &FadeAnimation{}, true
+	case Path:
+		return &PathAnimation{}, true
+	case Color:
+		return &ColorAnimation{}, true
+	case Bounce:
+		return &BounceAnimation{}, true
+	case Wave:
+		return &WaveAnimation{}, true
+	case Blink:
+		return &BlinkAnimation{}, true
+	}
+	return nil, false
+}
+
+// ToSpec 将内置动画转换为可序列化的描述，自定义动画无法还原，返回错误
+func ToSpec(anim Animation) (Spec, error) {
+	if anim == nil {
+		return Spec{}, fmt.Errorf("%w: animation is nil", errors.ErrInvalidAnimation)
+	}
+	if _, ok := newAnimation(anim.GetType()); !ok {
+		return Spec{}, fmt.Errorf("%w: unknown animation type %q", errors.ErrInvalidAnimation, anim.GetType())
+	}
+	params, err := json.Marshal(anim)
+	if err != nil {
+		return Spec{}, fmt.Errorf("%w: %s animation: %v", errors.ErrInvalidAnimation, anim.GetType(), err)
+	}
+
+	spec := Spec{Type: anim.GetType(), Params: params}
+	if v, ok := anim.(ReducedMotionVariant); ok && v.GetReduced() != nil {
+		reduced, err := ToSpec(v.GetReduced())
+		if err != nil {
+			return Spec{}, err
+		}
+		spec.Reduced = &reduced
+	}
+	return spec, nil
+}
+
+// FromSpec 由描述还原动画
+func FromSpec(spec Spec) (Animation, error) {
+	anim, ok := newAnimation(spec.Type)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown animation type %q", errors.ErrInvalidAnimation, spec.Type)
+	}
+	if err := json.Unmarshal(spec.Params, anim); err != nil {
+		return nil, fmt.Errorf("%w: %s animation: %v", errors.ErrInvalidAnimation, spec.Type, err)
+	}
+	if anim.GetType() != spec.Type {
+		return nil, fmt.Errorf("%w: params describe a %q animation, want %q", errors.ErrInvalidAnimation, anim.GetType(), spec.Type)
+	}
+
+	base := anim.(interface{ base() *BaseAnimation }).base()
+	if base.Attributes == nil {
+		base.Attributes = make(map[string]string)
+	}
+	if spec.Reduced != nil {
+		reduced, err := FromSpec(*spec.Reduced)
+		if err != nil {
+			return nil, err
+		}
+		base.Reduced = reduced
+	}
+	return anim, nil
+}
+
+// base 获取动画的基础属性，内置动画通过嵌入 BaseAnimation 获得该方法
+func (a *BaseAnimation) base() *BaseAnimation {
+	return a
+}
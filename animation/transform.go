@@ -50,3 +50,40 @@ func (a *TransformAnimation) GenerateSVG() string {
 
 	return svg
 }
+
+// Sample 计算变换动画在时刻t的变换
+func (a *TransformAnimation) Sample(t float64) (Effect, bool) {
+	p, ok := a.progress(t, false)
+	if !ok {
+		return Effect{}, false
+	}
+	return Effect{
+		TargetID:  a.TargetID,
+		Transform: a.TransformType + "(" + interpolateValue(a.From, a.To, p) + ")",
+		Additive:  true,
+	}, true
+}
+
+// GenerateCSS 生成变换动画的CSS关键帧
+// translate、scale、rotate 使用独立的CSS变换属性，可以与其他动画叠加
+func (a *TransformAnimation) GenerateCSS(name string) CSSRule {
+	value := func(v string) string {
+		switch a.TransformType {
+		case "translate":
+			return "translate: " + cssTranslate(v)
+		case "scale":
+			return "scale: " + cssScale(v)
+		case "rotate":
+			return "rotate: " + cssAngle(v)
+		default:
+			return "transform: " + a.TransformType + "(" + cssAngle(v) + ")"
+		}
+	}
+	return CSSRule{
+		Keyframes: writeKeyframes(name, []cssKeyframe{
+			{offset: 0, decl: value(a.From)},
+			{offset: 1, decl: value(a.To)},
+		}),
+		Animation: a.cssAnimation(name, "linear", false),
+	}
+}
@@ -1,8 +1,11 @@
 package animation
 
 import (
+	"fmt"
 	"strings"
 	"sync"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
 )
 
 var (
@@ -55,6 +58,7 @@ type BaseAnimation struct {
 	Delay       float64           // 延迟时间（秒）
 	TargetID    string            // 目标元素ID
 	Attributes  map[string]string // 动画属性
+	Reduced     Animation         `json:"-"` // 减弱动态效果时使用的替代动画，nil表示直接停用
 }
 
 // GetTargetID 获取目标元素ID
@@ -67,16 +71,89 @@ func (a *BaseAnimation) GetType() AnimationType {
 	return a.Type
 }
 
+// Validate 校验动画的通用属性
+func (a *BaseAnimation) Validate() error {
+	switch {
+	case a.TargetID == "":
+		return fmt.Errorf("%w: %s animation has no target id", errors.ErrInvalidAnimation, a.Type)
+	case a.Duration <= 0:
+		return fmt.Errorf("%w: %s animation on %q has non-positive duration %g", errors.ErrInvalidAnimation, a.Type, a.TargetID, a.Duration)
+	case a.Delay < 0:
+		return fmt.Errorf("%w: %s animation on %q has negative delay %g", errors.ErrInvalidAnimation, a.Type, a.TargetID, a.Delay)
+	case a.RepeatCount < -1:
+		return fmt.Errorf("%w: %s animation on %q has invalid repeat count %d", errors.ErrInvalidAnimation, a.Type, a.TargetID, a.RepeatCount)
+	}
+	return nil
+}
+
+// Validator 可以校验自身参数的动画，内置动画均实现了该接口
+type Validator interface {
+	Validate() error
+}
+
+// SetReduced 设置减弱动态效果时使用的替代动画，例如用轻微的淡入淡出代替旋转或弹跳
+func (a *BaseAnimation) SetReduced(reduced Animation) {
+	a.Reduced = reduced
+}
+
+// GetReduced 获取减弱动态效果时使用的替代动画
+func (a *BaseAnimation) GetReduced() Animation {
+	return a.Reduced
+}
+
+// ReducedMotionVariant 带有减弱动态效果替代版本的动画，内置动画均实现了该接口
+type ReducedMotionVariant interface {
+	Animation
+	// GetReduced 获取替代动画，nil表示直接停用
+	GetReduced() Animation
+}
+
 // Manager 动画管理器，负责管理所有动画
 type Manager struct {
-	animations []Animation
+	animations    []Animation
+	mode          Mode
+	reducedMotion bool
 }
 
 // NewAnimationManager 创建一个新的动画管理器
 func NewAnimationManager() *Manager {
 	return &Manager{
 		animations: make([]Animation, 0, 10), // 预分配容量
+		mode:       ModeSMIL,
+	}
+}
+
+// SetMode 设置动画输出模式
+func (m *Manager) SetMode(mode Mode) {
+	m.mode = mode
+}
+
+// Mode 获取实际使用的动画输出模式
+// SMIL动画无法响应媒体查询，启用减弱动态效果时总是使用CSS模式
+func (m *Manager) Mode() Mode {
+	if m.reducedMotion {
+		return ModeCSS
 	}
+	return m.mode
+}
+
+// ReducedMotion 是否响应 prefers-reduced-motion 媒体查询
+func (m *Manager) ReducedMotion() bool {
+	return m.reducedMotion
+}
+
+// SetReducedMotion 设置是否响应 prefers-reduced-motion 媒体查询
+// 启用后，用户要求减弱动态效果时停用所有动画，设置了替代动画的则改为播放替代动画
+func (m *Manager) SetReducedMotion(enabled bool) {
+	m.reducedMotion = enabled
+}
+
+// Generate 按当前输出模式生成动画代码
+func (m *Manager) Generate() string {
+	if m.Mode() == ModeCSS {
+		return m.GenerateCSSAnimations()
+	}
+	return m.GenerateSVGAnimations()
 }
 
 // AddAnimation 添加一个动画
@@ -84,6 +161,35 @@ func (m *Manager) AddAnimation(animation Animation) {
 	m.animations = append(m.animations, animation)
 }
 
+// RemoveAnimation 移除一个动画，动画不存在时返回 false
+func (m *Manager) RemoveAnimation(animation Animation) bool {
+	for i, anim := range m.animations {
+		if sameAnimation(anim, animation) {
+			m.animations = append(m.animations[:i:i], m.animations[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear 移除所有动画
+func (m *Manager) Clear() {
+	m.animations = make([]Animation, 0, 10)
+}
+
+// Clone 复制动画管理器，内置动画和实现了 Cloner 的自定义动画会被深拷贝，修改副本不会影响原管理器
+func (m *Manager) Clone() *Manager {
+	c := &Manager{
+		animations:    make([]Animation, len(m.animations), len(m.animations)+10),
+		mode:          m.mode,
+		reducedMotion: m.reducedMotion,
+	}
+	for i, anim := range m.animations {
+		c.animations[i] = Clone(anim)
+	}
+	return c
+}
+
 // GetAnimations 获取所有动画
 func (m *Manager) GetAnimations() []Animation {
 	return m.animations
@@ -63,19 +63,47 @@ func (a *WaveAnimation) generateWavePath() string {
 	var path strings.Builder
 	path.WriteString("M0,0 ")
 
-	// 生成正弦波路径
+	for _, p := range a.wavePoints() {
+		path.WriteString(fmt.Sprintf("L%g,%g ", p[0], p[1]))
+	}
+
+	return path.String()
+}
+
+// wavePoints 计算正弦波路径上的点
+func (a *WaveAnimation) wavePoints() [][2]float64 {
 	points := 20 // 路径点数量
+	out := make([][2]float64, 0, points+1)
 	for i := 0; i <= points; i++ {
 		x := float64(i) / float64(points) * 100 // 0-100 范围
 		// 计算正弦波 y 值
 		y := a.Amplitude * math.Sin(a.Frequency*x*math.Pi/180)
 
 		if a.Direction == "horizontal" {
-			path.WriteString(fmt.Sprintf("L%g,%g ", x, y))
+			out = append(out, [2]float64{x, y})
 		} else { // vertical
-			path.WriteString(fmt.Sprintf("L%g,%g ", y, x))
+			out = append(out, [2]float64{y, x})
 		}
 	}
+	return out
+}
 
-	return path.String()
+// Sample 计算波浪动画在时刻t的位置
+func (a *WaveAnimation) Sample(t float64) (Effect, bool) {
+	p, ok := a.progress(t, false)
+	if !ok {
+		return Effect{}, false
+	}
+	return Effect{
+		TargetID: a.TargetID,
+		Motion:   &Motion{Path: a.generateWavePath(), Progress: p, Rotate: "0"},
+	}, true
+}
+
+// GenerateCSS 生成波浪动画的CSS关键帧，按路径长度分配各点的时间
+func (a *WaveAnimation) GenerateCSS(name string) CSSRule {
+	return CSSRule{
+		Keyframes: writeKeyframes(name, pacedKeyframes(a.wavePoints())),
+		Animation: a.cssAnimation(name, "linear", false),
+	}
 }
@@ -0,0 +1,159 @@
+package pixelnebula
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+// BackgroundMode 背景模式
+type BackgroundMode string
+
+// 预定义背景模式常量
+const (
+	BackgroundTheme          BackgroundMode = ""                // 使用主题的背景色（默认）
+	BackgroundTransparent    BackgroundMode = "transparent"     // 透明背景
+	BackgroundSolid          BackgroundMode = "solid"           // 纯色背景
+	BackgroundLinearGradient BackgroundMode = "linear-gradient" // 由背景色派生的线性渐变
+	BackgroundRadialGradient BackgroundMode = "radial-gradient" // 由背景色派生的径向渐变
+	BackgroundPattern        BackgroundMode = "pattern"         // 由背景色派生的点状图案
+)
+
+// backgroundID 背景渐变或图案的元素ID
+const backgroundID = "avatar-bg"
+
+var (
+	// envFillRegex 匹配背景形状的填充色
+	envFillRegex = regexp.MustCompile(`(<[a-zA-Z]+ id='env'[^>]*?style=")fill:([^;"]*);`)
+	// hexColorRegex 合法的十六进制颜色
+	hexColorRegex = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+)
+
+// SetBackground 设置背景模式
+// color 为纯色背景的颜色；对渐变和图案模式，color 为空时使用主题的背景色作为基础色
+func (sb *SVGBuilder) SetBackground(mode BackgroundMode, color string) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	switch mode {
+	case BackgroundTheme, BackgroundTransparent:
+	case BackgroundSolid:
+		if color == "" {
+			sb.hasError = errors.ErrInvalidBackground
+			return sb
+		}
+		fallthrough
+	case BackgroundLinearGradient, BackgroundRadialGradient, BackgroundPattern:
+		if color != "" && !hexColorRegex.MatchString(color) {
+			sb.hasError = errors.ErrInvalidBackground
+			return sb
+		}
+	default:
+		sb.hasError = errors.ErrInvalidBackground
+		return sb
+	}
+	sb.background = mode
+	sb.bgColor = color
+	return sb
+}
+
+// applyBackground 按背景模式替换背景形状的填充
+func applyBackground(svg string, mode BackgroundMode, color string) (string, error) {
+	if mode == BackgroundTheme || mode == BackgroundTransparent {
+		return svg, nil
+	}
+	if !strings.HasPrefix(svg, artworkSVGStart) {
+		return "", errors.ErrInvalidSVG
+	}
+	m := envFillRegex.FindStringSubmatchIndex(svg)
+	if m == nil {
+		// 不包含背景形状时无需处理
+		return svg, nil
+	}
+
+	base := color
+	if base == "" {
+		base = svg[m[4]:m[5]]
+	}
+	rgb, ok := parseRGB(base)
+	if !ok {
+		return "", errors.ErrInvalidBackground
+	}
+
+	var defs, fill string
+	switch mode {
+	case BackgroundSolid:
+		fill = formatRGB(rgb)
+	case BackgroundLinearGradient:
+		defs = fmt.Sprintf(`<linearGradient id="%s" x1="0" y1="0" x2="0" y2="1"><stop offset="0" stop-color="%s"/><stop offset="1" stop-color="%s"/></linearGradient>`,
+			backgroundID, formatRGB(mixRGB(rgb, [3]float64{255, 255, 255}, 0.3)), formatRGB(mixRGB(rgb, [3]float64{}, 0.2)))
+		fill = "url(#" + backgroundID + ") " + formatRGB(rgb)
+	case BackgroundRadialGradient:
+		defs = fmt.Sprintf(`<radialGradient id="%s" cx="0.5" cy="0.4" r="0.6"><stop offset="0" stop-color="%s"/><stop offset="1" stop-color="%s"/></radialGradient>`,
+			backgroundID, formatRGB(mixRGB(rgb, [3]float64{255, 255, 255}, 0.3)), formatRGB(mixRGB(rgb, [3]float64{}, 0.2)))
+		fill = "url(#" + backgroundID + ") " + formatRGB(rgb)
+	case BackgroundPattern:
+		defs = fmt.Sprintf(`<pattern id="%s" width="14" height="14" patternUnits="userSpaceOnUse"><rect width="14" height="14" fill="%s"/><circle cx="7" cy="7" r="1.5" fill="%s"/></pattern>`,
+			backgroundID, formatRGB(rgb), formatRGB(mixRGB(rgb, [3]float64{}, 0.12)))
+		fill = "url(#" + backgroundID + ") " + formatRGB(rgb)
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(svg) + len(defs) + 32)
+	sb.WriteString(artworkSVGStart)
+	if defs != "" {
+		sb.WriteString("<defs>")
+		sb.WriteString(defs)
+		sb.WriteString("</defs>")
+	}
+	sb.WriteString(svg[len(artworkSVGStart):m[2]])
+	sb.WriteString(svg[m[2]:m[3]])
+	sb.WriteString("fill:")
+	sb.WriteString(fill)
+	sb.WriteString(";")
+	sb.WriteString(svg[m[1]:])
+	return sb.String(), nil
+}
+
+// parseRGB 解析 #rgb 或 #rrggbb 颜色，# 可省略
+func parseRGB(s string) ([3]float64, bool) {
+	m := hexColorRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return [3]float64{}, false
+	}
+	hex := m[1]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return [3]float64{}, false
+	}
+	return [3]float64{float64(n >> 16 & 0xff), float64(n >> 8 & 0xff), float64(n & 0xff)}, true
+}
+
+// mixRGB 将颜色按比例混合到目标颜色
+func mixRGB(c, target [3]float64, f float64) [3]float64 {
+	var out [3]float64
+	for i := range out {
+		out[i] = c[i] + (target[i]-c[i])*f
+	}
+	return out
+}
+
+// formatRGB 将颜色格式化为 #rrggbb
+func formatRGB(c [3]float64) string {
+	var b [3]uint8
+	for i, v := range c {
+		if v < 0 {
+			v = 0
+		} else if v > 255 {
+			v = 255
+		}
+		b[i] = uint8(v + 0.5)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", b[0], b[1], b[2])
+}
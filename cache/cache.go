@@ -2,6 +2,7 @@ package cache
 
 import (
 	"container/list"
+	"log/slog"
 	"strconv"
 	"strings"
 	"sync"
@@ -53,6 +54,7 @@ type CacheKey struct {
 	SansEnv bool
 	Theme   int
 	Part    int
+	Digest  string // 所有生成输入的内容摘要
 }
 
 // String 返回缓存键的字符串表示
@@ -76,6 +78,10 @@ func (k CacheKey) String() string {
 	sb.WriteString(strconv.Itoa(k.Theme))
 	sb.WriteByte('_')
 	sb.WriteString(strconv.Itoa(k.Part))
+	if k.Digest != "" {
+		sb.WriteByte('_')
+		sb.WriteString(k.Digest)
+	}
 
 	// 获取结果
 	result := sb.String()
@@ -113,6 +119,7 @@ type PNCache struct {
 	Hits         int      // 缓存命中次数
 	Misses       int      // 缓存未命中次数
 	Monitor      *Monitor // 缓存监控器
+	logger       loggerRef
 }
 
 // NewCache 创建一个新的缓存实例
@@ -291,6 +298,8 @@ func (c *PNCache) evictItem() {
 			}
 		}
 
+		c.logger.get().Debug("cache item evicted", slog.String("eviction", c.Options.EvictionType), slog.Int("size", len(c.Items)))
+
 		// 重置并归还缓存项
 		cacheItem.Reset()
 		cacheItemPool.Put(cacheItem)
@@ -313,6 +322,7 @@ func (c *PNCache) Clear() {
 	c.EvictionList = list.New()
 	c.Hits = 0
 	c.Misses = 0
+	c.logger.get().Info("cache cleared")
 }
 
 // Size 返回当前缓存项数量
@@ -364,6 +374,9 @@ func (c *PNCache) RemoveExpired() int {
 		}
 	}
 
+	if count > 0 {
+		c.logger.get().Debug("expired cache items removed", slog.Int("count", count), slog.Int("size", len(c.Items)))
+	}
 	return count
 }
 
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// discardLogger 未设置日志记录器时使用，不输出任何日志
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// loggerRef 可并发读写的日志记录器引用，零值不输出日志
+type loggerRef struct {
+	p atomic.Pointer[slog.Logger]
+}
+
+// set 设置日志记录器，nil 表示不输出日志
+func (r *loggerRef) set(l *slog.Logger) {
+	r.p.Store(l)
+}
+
+// get 获取日志记录器
+func (r *loggerRef) get() *slog.Logger {
+	if l := r.p.Load(); l != nil {
+		return l
+	}
+	return discardLogger
+}
+
+// SetLogger 设置缓存的日志记录器，同时作用于已创建的监控器，nil 表示不输出日志
+func (c *PNCache) SetLogger(l *slog.Logger) {
+	c.logger.set(l)
+	if m := c.GetMonitor(); m != nil {
+		m.SetLogger(l)
+	}
+}
+
+// Logger 获取缓存的日志记录器
+func (c *PNCache) Logger() *slog.Logger {
+	return c.logger.get()
+}
+
+// SetLogger 设置监控器的日志记录器，nil 表示不输出日志
+func (m *Monitor) SetLogger(l *slog.Logger) {
+	m.logger.set(l)
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"log/slog"
 	"sync"
 	"time"
 )
@@ -52,17 +53,23 @@ type Monitor struct {
 	mutex         sync.RWMutex
 	stopChan      chan struct{}
 	isRunning     bool
+	logger        loggerRef
 }
 
 // NewMonitor 创建一个新的缓存监控器
+// 监控器沿用缓存的日志记录器
 func NewMonitor(cache *PNCache, options MonitorOptions) *Monitor {
-	return &Monitor{
+	m := &Monitor{
 		options:       options,
 		cache:         cache,
 		sampleHistory: make([]CacheStats, 0, 100), // 预分配100个样本的容量
 		stopChan:      make(chan struct{}),
 		isRunning:     false,
 	}
+	if cache != nil {
+		m.logger.set(cache.logger.p.Load())
+	}
+	return m
 }
 
 // Start 启动监控器
@@ -75,6 +82,9 @@ func (m *Monitor) Start() {
 	m.isRunning = true
 	m.mutex.Unlock()
 
+	m.logger.get().Debug("cache monitor started",
+		slog.Duration("sample_interval", m.options.SampleInterval),
+		slog.Duration("adjust_interval", m.options.AdjustInterval))
 	go m.monitorRoutine()
 }
 
@@ -89,6 +99,7 @@ func (m *Monitor) Stop() {
 	m.mutex.Unlock()
 
 	m.stopChan <- struct{}{}
+	m.logger.get().Debug("cache monitor stopped")
 }
 
 // GetStats 获取当前缓存统计信息
@@ -245,9 +256,17 @@ func (m *Monitor) adjustCache() {
 
 	// 应用新的缓存选项
 	if newSize != cacheOptions.Size || newExpiration != cacheOptions.Expiration {
+		oldSize, oldExpiration := cacheOptions.Size, cacheOptions.Expiration
 		cacheOptions.Size = newSize
 		cacheOptions.Expiration = newExpiration
 		m.cache.UpdateOptions(cacheOptions)
+		m.logger.get().Info("cache adjusted",
+			slog.Float64("avg_hit_rate", avgHitRate),
+			slog.Float64("target_hit_rate", m.options.TargetHitRate),
+			slog.Int("old_size", oldSize),
+			slog.Int("new_size", newSize),
+			slog.Duration("old_expiration", oldExpiration),
+			slog.Duration("new_expiration", newExpiration))
 
 		// 更新最后调整时间
 		m.stats.LastAdjusted = time.Now()
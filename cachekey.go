@@ -0,0 +1,69 @@
+package pixelnebula
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/landaiqing/go-pixelnebula/animation"
+	"github.com/landaiqing/go-pixelnebula/cache"
+	"github.com/landaiqing/go-pixelnebula/style"
+)
+
+// cacheKey 生成内容寻址的缓存键，keys 为 partKeys 计算的各部分风格和主题
+// 摘要覆盖所有影响画布的输入：ID、选择算法、各部分实际使用的风格形状和颜色及动画，
+// 风格和主题按内容计入，修改风格集、主题集或动画后不会命中旧的缓存项
+// 尺寸、背景、裁剪、无障碍信息、元数据和输出格式都不计入摘要，这依赖于缓存只保存应用这些设置之前的画布；
+// 如果改为缓存之后的结果，必须把这些设置加入摘要
+func (pn *PixelNebula) cacheKey(id string, sansEnv bool, opts *PNOptions, keys map[style.ShapeType][2]int) cache.CacheKey {
+	h := sha256.New()
+	field := func(name string, value any) {
+		fmt.Fprintf(h, "%s=%q\n", name, fmt.Sprint(value))
+	}
+
+	selection := pn.Selection
+	if selection == 0 {
+		selection = SelectionV1
+	}
+	field("id", id)
+	field("sansEnv", sansEnv)
+	field("selection", selection)
+	field("svgEnd", pn.SvgEnd)
+	for _, part := range partOrder {
+		key := keys[part]
+		name, _ := pn.StyleManager.GetStyleName(key[0])
+		shape, _ := pn.StyleManager.GetShape(key[0], part)
+		field("part", part)
+		field("key", key)
+		field("style", name)
+		field("shape", shape)
+		if themePart, err := pn.ThemeManager.GetTheme(key[0], key[1]); err == nil {
+			field("colors", mergeColors(themePart[string(part)], opts.PartColors[part]))
+		}
+	}
+	writeAnimations(field, pn.AnimManager)
+
+	return cache.CacheKey{
+		Id:      id,
+		SansEnv: sansEnv,
+		Theme:   opts.ThemeIndex,
+		Part:    opts.StyleIndex,
+		Digest:  hex.EncodeToString(h.Sum(nil)),
+	}
+}
+
+// writeAnimations 将动画计入摘要，内置动画按参数计入，自定义动画按生成的代码计入
+func writeAnimations(field func(string, any), m *animation.Manager) {
+	field("animationMode", m.Mode())
+	field("reducedMotion", m.ReducedMotion())
+	for _, anim := range m.GetAnimations() {
+		spec, err := animation.ToSpec(anim)
+		if err != nil {
+			field("animation", fmt.Sprintf("%T %s %s", anim, anim.GetTargetID(), anim.GenerateSVG()))
+			continue
+		}
+		data, _ := json.Marshal(spec)
+		field("animation", string(data))
+	}
+}
@@ -0,0 +1,247 @@
+package converter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"sort"
+	"time"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+// EncodeGIF 将一组帧编码为动画GIF
+// delay 为每帧的显示时间，loopCount 为播放次数，0 表示无限循环
+// GIF 只支持1位透明度，半透明像素以50%为阈值处理
+func EncodeGIF(frames []*image.RGBA, delay time.Duration, loopCount int) ([]byte, error) {
+	if len(frames) == 0 {
+		return nil, errors.ErrNoFrames
+	}
+
+	pal := buildPalette(frames)
+	lookup := make(map[color.NRGBA]uint8, len(pal))
+
+	anim := &gif.GIF{
+		Image:     make([]*image.Paletted, 0, len(frames)),
+		Delay:     make([]int, 0, len(frames)),
+		Disposal:  make([]byte, 0, len(frames)),
+		LoopCount: gifLoopCount(loopCount),
+	}
+	centis := int(delay / (10 * time.Millisecond))
+	if centis < 2 {
+		centis = 2 // 多数浏览器会将小于2的延迟按10处理
+	}
+
+	for _, frame := range frames {
+		b := frame.Bounds()
+		p := image.NewPaletted(b, pal)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := color.NRGBAModel.Convert(frame.RGBAAt(x, y)).(color.NRGBA)
+				if c.A < 0x80 {
+					p.SetColorIndex(x, y, 0)
+					continue
+				}
+				c.A = 0xff
+				idx, ok := lookup[c]
+				if !ok {
+					idx = uint8(pal.Index(c))
+					lookup[c] = idx
+				}
+				p.SetColorIndex(x, y, idx)
+			}
+		}
+		anim.Image = append(anim.Image, p)
+		anim.Delay = append(anim.Delay, centis)
+		anim.Disposal = append(anim.Disposal, gif.DisposalBackground)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gifLoopCount 将播放次数转换为GIF的循环次数语义
+func gifLoopCount(loopCount int) int {
+	switch {
+	case loopCount <= 0:
+		return 0
+	case loopCount == 1:
+		return -1
+	default:
+		return loopCount - 1
+	}
+}
+
+// buildPalette 统计所有帧中出现最多的255种不透明颜色，索引0保留为透明色
+func buildPalette(frames []*image.RGBA) color.Palette {
+	counts := make(map[color.NRGBA]int)
+	for _, frame := range frames {
+		b := frame.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := color.NRGBAModel.Convert(frame.RGBAAt(x, y)).(color.NRGBA)
+				if c.A < 0x80 {
+					continue
+				}
+				c.A = 0xff
+				counts[c]++
+			}
+		}
+	}
+
+	colors := make([]color.NRGBA, 0, len(counts))
+	for c := range counts {
+		colors = append(colors, c)
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		ci, cj := counts[colors[i]], counts[colors[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		// 保证相同输入得到相同的调色板
+		return colorKey(colors[i]) < colorKey(colors[j])
+	})
+	if len(colors) > 255 {
+		colors = colors[:255]
+	}
+
+	pal := make(color.Palette, 0, len(colors)+1)
+	pal = append(pal, color.NRGBA{})
+	for _, c := range colors {
+		pal = append(pal, c)
+	}
+	if len(pal) == 1 {
+		pal = append(pal, color.NRGBA{A: 0xff})
+	}
+	return pal
+}
+
+// colorKey 将颜色打包为整数用于排序
+func colorKey(c color.NRGBA) uint32 {
+	return uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+}
+
+// EncodeAPNG 将一组帧编码为动画PNG（APNG）
+// delay 为每帧的显示时间，loopCount 为播放次数，0 表示无限循环
+func EncodeAPNG(frames []*image.RGBA, delay time.Duration, loopCount int) ([]byte, error) {
+	if len(frames) == 0 {
+		return nil, errors.ErrNoFrames
+	}
+	b := frames[0].Bounds()
+	for _, f := range frames[1:] {
+		if f.Bounds().Size() != b.Size() {
+			return nil, errors.ErrInvalidFrameSize
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+
+	// IHDR：8位RGBA
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(b.Dx()))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(b.Dy()))
+	ihdr[8] = 8
+	ihdr[9] = 6
+	writeChunk(&buf, "IHDR", ihdr)
+
+	// acTL：帧数和播放次数
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+	if loopCount > 0 {
+		binary.BigEndian.PutUint32(actl[4:], uint32(loopCount))
+	}
+	writeChunk(&buf, "acTL", actl)
+
+	ms := delay.Milliseconds()
+	if ms > 0xffff {
+		ms = 0xffff
+	}
+
+	seq := uint32(0)
+	for i, frame := range frames {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(b.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(b.Dy()))
+		binary.BigEndian.PutUint16(fctl[20:], uint16(ms))
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		// dispose_op 与 blend_op 均为0：每帧完整替换画布
+		writeChunk(&buf, "fcTL", fctl)
+		seq++
+
+		data, err := compressFrame(frame)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			writeChunk(&buf, "IDAT", data)
+			continue
+		}
+		fdat := make([]byte, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, seq)
+		copy(fdat[4:], data)
+		writeChunk(&buf, "fdAT", fdat)
+		seq++
+	}
+
+	writeChunk(&buf, "IEND", nil)
+	return buf.Bytes(), nil
+}
+
+// compressFrame 将帧转换为非预乘RGBA扫描线，使用Sub滤波后进行zlib压缩
+func compressFrame(frame *image.RGBA) ([]byte, error) {
+	b := frame.Bounds()
+	nrgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), frame, b.Min, draw.Src)
+
+	var out bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&out, zlib.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	rowLen := b.Dx() * 4
+	line := make([]byte, rowLen+1)
+	for y := 0; y < b.Dy(); y++ {
+		row := nrgba.Pix[y*nrgba.Stride : y*nrgba.Stride+rowLen]
+		line[0] = 1 // Sub 滤波
+		for i := 0; i < rowLen; i++ {
+			left := byte(0)
+			if i >= 4 {
+				left = row[i-4]
+			}
+			line[i+1] = row[i] - left
+		}
+		if _, err := zw.Write(line); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// writeChunk 写入一个PNG数据块
+func writeChunk(buf *bytes.Buffer, name string, data []byte) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], name)
+	buf.Write(header[:])
+	buf.Write(data)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	buf.Write(sum[:])
+}
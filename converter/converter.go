@@ -1,13 +1,26 @@
 package converter
 
 import (
+	"bytes"
 	"encoding/base64"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
 	"regexp"
 	"strings"
 )
 
+const (
+	// DefaultJPEGQuality 默认JPEG压缩质量
+	DefaultJPEGQuality = 90
+)
+
 var (
 	colorAttrRegex = regexp.MustCompile(`(?i)(fill|stroke):(?:#none|transparent)(?:;|\s|"|'|$)`)
+	// animateTransformRegex 匹配旋转等变换动画元素
+	animateTransformRegex = regexp.MustCompile(`<animateTransform[^>]*>`)
 )
 
 type Converter interface {
@@ -17,23 +30,59 @@ type Converter interface {
 }
 
 type SVGConverter struct {
-	svgData []byte
-	width   int
-	height  int
+	svgData     []byte
+	rawData     []byte      // 未经预处理的原始SVG，用于栅格化
+	width       int         // 输出宽度（像素），不大于0时使用SVG自身尺寸
+	height      int         // 输出高度（像素），不大于0时使用SVG自身尺寸
+	scale       float64     // DPI缩放系数，实际像素大小为宽高乘以该系数
+	background  color.Color // JPEG背景色，JPEG不支持透明通道
+	jpegQuality int         // JPEG压缩质量，范围1-100
 }
 
 func NewSVGConverter(svgData []byte, width, height int) *SVGConverter {
 	processed := preprocessSVG(svgData)
 	return &SVGConverter{
-		svgData: processed,
-		width:   width,
-		height:  height,
+		svgData:     processed,
+		rawData:     svgData,
+		width:       width,
+		height:      height,
+		scale:       1,
+		background:  color.White,
+		jpegQuality: DefaultJPEGQuality,
+	}
+}
+
+// WithBackground 设置JPEG输出的背景色，默认为白色
+func (c *SVGConverter) WithBackground(bg color.Color) *SVGConverter {
+	if bg != nil {
+		c.background = bg
+	}
+	return c
+}
+
+// WithScale 设置DPI缩放系数，例如2表示输出两倍像素密度的图像，不大于0时按1处理
+// 缩放后的宽高超出 MaxImageSize 时，栅格化返回 ErrInvalidSize
+func (c *SVGConverter) WithScale(scale float64) *SVGConverter {
+	if scale <= 0 {
+		scale = 1
+	}
+	c.scale = scale
+	return c
+}
+
+// WithJPEGQuality 设置JPEG压缩质量，超出1-100范围时使用默认值
+func (c *SVGConverter) WithJPEGQuality(quality int) *SVGConverter {
+	if quality < 1 || quality > 100 {
+		quality = DefaultJPEGQuality
 	}
+	c.jpegQuality = quality
+	return c
 }
 
+// preprocessSVG 预处理 ToBase64 输出的SVG数据，栅格化使用未经处理的原始数据
 func preprocessSVG(data []byte) []byte {
 	// 1. 移除动画元素
-	data = regexp.MustCompile(`<animateTransform[^>]*>`).ReplaceAll(data, []byte{})
+	data = animateTransformRegex.ReplaceAll(data, []byte{})
 
 	// 2. 替换 fill:#none, fill:transparent 为 fill:#000000
 	processed := colorAttrRegex.ReplaceAllStringFunc(string(data), func(match string) string {
@@ -50,18 +99,51 @@ func (c *SVGConverter) ToBase64() (string, error) {
 	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(c.svgData), nil
 }
 
+// ToImage returns the SVG data rasterized into an RGBA image,
+// sized to the converter's width and height multiplied by its scale.
+// Animations, CSS rules and filters are ignored; gradients are approximated by their first stop color.
+func (c *SVGConverter) ToImage() (*image.RGBA, error) {
+	return rasterize(c.rawData, c.width, c.height, c.scale)
+}
+
+// RenderImage rasterizes SVG data into an RGBA image of width*scale x height*scale pixels,
+// ready to be composited with image/draw. A width or height <= 0 falls back to the SVG's own size.
+// Sizes above MaxImageSize pixels in either dimension are rejected with ErrInvalidSize.
+func RenderImage(svgData []byte, width, height int, scale float64) (*image.RGBA, error) {
+	return rasterize(svgData, width, height, scale)
+}
+
 // ToPNG returns the SVG data as a PNG image.
-// Note: This is not implemented yet.
-// Deprecated: It can't be perfectly implemented for the time being, so it's better to Use ToBase64 instead.
+// The SVG is rasterized in pure Go, see ToImage for what is supported.
 func (c *SVGConverter) ToPNG() ([]byte, error) {
-	// TODO: implement
-	return nil, nil
+	img, err := c.ToImage()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // ToJPEG returns the SVG data as a JPEG image.
-// Note: This is not implemented yet.
-// Deprecated: It can't be perfectly implemented for the time being, so it's better to Use ToBase64 instead.
+// Since JPEG has no alpha channel, the image is composited onto the converter's background color first.
 func (c *SVGConverter) ToJPEG() ([]byte, error) {
-	// TODO: implement
-	return nil, nil
+	img, err := c.ToImage()
+	if err != nil {
+		return nil, err
+	}
+
+	// 将透明图像合成到背景色上
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(c.background), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: c.jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,113 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+// Bounds 计算SVG片段中所有形状的几何包围盒（用户坐标系，不含描边宽度）
+// 片段可以是完整的SVG文档，也可以是若干并列的形状元素；没有可绘制的形状时返回全为0的空矩形
+func Bounds(svgFragment []byte) (minX, minY, maxX, maxY float64, err error) {
+	data := make([]byte, 0, len(svgFragment)+7)
+	data = append(data, "<g>"...)
+	data = append(data, svgFragment...)
+	data = append(data, "</g>"...)
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	stack := []matrix{identityMatrix}
+
+	for {
+		tok, tokErr := dec.Token()
+		if tokErr == io.EOF {
+			break
+		}
+		if tokErr != nil {
+			return 0, 0, 0, 0, errors.ErrInvalidSVG
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			attrs := attrMap(t.Attr)
+			m := stack[len(stack)-1]
+			if tf, ok := attrs["transform"]; ok {
+				m = m.mul(parseTransform(tf))
+			}
+			stack = append(stack, m)
+
+			pd, _, pathErr := elementPath(t.Name.Local, attrs)
+			if pathErr != nil {
+				return 0, 0, 0, 0, pathErr
+			}
+			for _, l := range pd.flatten(m) {
+				for _, p := range l.pts {
+					minX, minY = math.Min(minX, p.x), math.Min(minY, p.y)
+					maxX, maxY = math.Max(maxX, p.x), math.Max(maxY, p.y)
+				}
+			}
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if math.IsInf(minX, 1) {
+		return 0, 0, 0, 0, nil
+	}
+	return minX, minY, maxX, maxY, nil
+}
+
+// PathPoint 计算点沿路径移动到指定进度（0-1，按路径长度计算）时的坐标及切线角度（度）
+func PathPoint(d string, progress float64) (x, y, angle float64, err error) {
+	pd, err := parsePathData(d)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	// 将所有子路径首尾相接，按长度参数化
+	var pts []point
+	for _, l := range pd.flatten(identityMatrix) {
+		pts = append(pts, l.pts...)
+		if l.closed && len(l.pts) > 0 {
+			pts = append(pts, l.pts[0])
+		}
+	}
+	if len(pts) == 0 {
+		return 0, 0, 0, errors.ErrInvalidSVG
+	}
+	if len(pts) == 1 {
+		return pts[0].x, pts[0].y, 0, nil
+	}
+
+	total := 0.0
+	for i := 1; i < len(pts); i++ {
+		total += math.Hypot(pts[i].x-pts[i-1].x, pts[i].y-pts[i-1].y)
+	}
+
+	target := clamp01(progress) * total
+	walked := 0.0
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		seg := math.Hypot(b.x-a.x, b.y-a.y)
+		if seg == 0 {
+			continue
+		}
+		if walked+seg >= target || i == len(pts)-1 {
+			f := math.Min(1, (target-walked)/seg)
+			angle = math.Atan2(b.y-a.y, b.x-a.x) * 180 / math.Pi
+			return a.x + (b.x-a.x)*f, a.y + (b.y-a.y)*f, angle, nil
+		}
+		walked += seg
+	}
+
+	last := pts[len(pts)-1]
+	return last.x, last.y, 0, nil
+}
@@ -0,0 +1,482 @@
+package converter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+// point 表示一个二维坐标点
+type point struct {
+	x, y float64
+}
+
+// matrix 表示二维仿射变换矩阵，顺序与SVG的matrix(a b c d e f)一致
+type matrix [6]float64
+
+// identityMatrix 单位矩阵
+var identityMatrix = matrix{1, 0, 0, 1, 0, 0}
+
+// mul 返回 m*n，即先应用n再应用m
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+// apply 对点应用变换
+func (m matrix) apply(p point) point {
+	return point{
+		x: m[0]*p.x + m[2]*p.y + m[4],
+		y: m[1]*p.x + m[3]*p.y + m[5],
+	}
+}
+
+// scale 返回变换的平均缩放系数，用于换算描边宽度
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+// pathSeg 表示一段路径命令，只包含 M、L、C、Z 四种规范化后的命令
+type pathSeg struct {
+	op  byte
+	pts [3]point
+}
+
+// pathData 规范化后的路径数据
+type pathData []pathSeg
+
+// pathScanner 路径数据词法扫描器
+type pathScanner struct {
+	s   string
+	pos int
+}
+
+// skipSeparators 跳过空白和逗号
+func (ps *pathScanner) skipSeparators() {
+	for ps.pos < len(ps.s) {
+		switch ps.s[ps.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			ps.pos++
+		default:
+			return
+		}
+	}
+}
+
+// hasNumber 判断下一个记号是否为数字
+func (ps *pathScanner) hasNumber() bool {
+	ps.skipSeparators()
+	if ps.pos >= len(ps.s) {
+		return false
+	}
+	c := ps.s[ps.pos]
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')
+}
+
+// number 读取一个数字，支持 "0.5.5"、"1-2"、"1e-3" 等紧凑写法
+func (ps *pathScanner) number() (float64, error) {
+	ps.skipSeparators()
+	start := ps.pos
+	if ps.pos < len(ps.s) && (ps.s[ps.pos] == '-' || ps.s[ps.pos] == '+') {
+		ps.pos++
+	}
+	seenDot := false
+	for ps.pos < len(ps.s) {
+		c := ps.s[ps.pos]
+		if c >= '0' && c <= '9' {
+			ps.pos++
+		} else if c == '.' && !seenDot {
+			seenDot = true
+			ps.pos++
+		} else {
+			break
+		}
+	}
+	if ps.pos < len(ps.s) && (ps.s[ps.pos] == 'e' || ps.s[ps.pos] == 'E') {
+		ps.pos++
+		if ps.pos < len(ps.s) && (ps.s[ps.pos] == '-' || ps.s[ps.pos] == '+') {
+			ps.pos++
+		}
+		for ps.pos < len(ps.s) && ps.s[ps.pos] >= '0' && ps.s[ps.pos] <= '9' {
+			ps.pos++
+		}
+	}
+	v, err := strconv.ParseFloat(ps.s[start:ps.pos], 64)
+	if err != nil {
+		return 0, errors.ErrInvalidSVG
+	}
+	return v, nil
+}
+
+// flag 读取圆弧命令中的单字符标志位
+func (ps *pathScanner) flag() (bool, error) {
+	ps.skipSeparators()
+	if ps.pos >= len(ps.s) {
+		return false, errors.ErrInvalidSVG
+	}
+	c := ps.s[ps.pos]
+	if c != '0' && c != '1' {
+		return false, errors.ErrInvalidSVG
+	}
+	ps.pos++
+	return c == '1', nil
+}
+
+// numbers 连续读取n个数字
+func (ps *pathScanner) numbers(n int) ([]float64, error) {
+	vals := make([]float64, n)
+	for i := range vals {
+		v, err := ps.number()
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+// parsePathData 解析SVG路径的d属性，并将所有命令规范化为绝对坐标的 M、L、C、Z
+func parsePathData(d string) (pathData, error) {
+	ps := &pathScanner{s: d}
+	var (
+		out          pathData
+		cur, start   point
+		lastCtrl     point
+		cmd          byte
+		hasLastCubic bool
+		hasLastQuad  bool
+	)
+
+	for {
+		ps.skipSeparators()
+		if ps.pos >= len(ps.s) {
+			break
+		}
+		c := ps.s[ps.pos]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			cmd = c
+			ps.pos++
+		} else if cmd == 0 {
+			return nil, errors.ErrInvalidSVG
+		} else if cmd == 'M' {
+			// M 后续的隐式坐标按 L 处理
+			cmd = 'L'
+		} else if cmd == 'm' {
+			cmd = 'l'
+		} else if cmd == 'Z' || cmd == 'z' {
+			return nil, errors.ErrInvalidSVG
+		}
+
+		rel := cmd >= 'a' && cmd <= 'z'
+		base := point{}
+		if rel {
+			base = cur
+		}
+
+		switch cmd {
+		case 'M', 'm':
+			v, err := ps.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			cur = point{base.x + v[0], base.y + v[1]}
+			start = cur
+			out = append(out, pathSeg{op: 'M', pts: [3]point{cur}})
+		case 'L', 'l':
+			v, err := ps.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			cur = point{base.x + v[0], base.y + v[1]}
+			out = append(out, pathSeg{op: 'L', pts: [3]point{cur}})
+		case 'H', 'h':
+			v, err := ps.number()
+			if err != nil {
+				return nil, err
+			}
+			cur = point{base.x + v, cur.y}
+			out = append(out, pathSeg{op: 'L', pts: [3]point{cur}})
+		case 'V', 'v':
+			v, err := ps.number()
+			if err != nil {
+				return nil, err
+			}
+			cur = point{cur.x, base.y + v}
+			out = append(out, pathSeg{op: 'L', pts: [3]point{cur}})
+		case 'C', 'c':
+			v, err := ps.numbers(6)
+			if err != nil {
+				return nil, err
+			}
+			c1 := point{base.x + v[0], base.y + v[1]}
+			c2 := point{base.x + v[2], base.y + v[3]}
+			cur = point{base.x + v[4], base.y + v[5]}
+			out = append(out, pathSeg{op: 'C', pts: [3]point{c1, c2, cur}})
+			lastCtrl = c2
+		case 'S', 's':
+			v, err := ps.numbers(4)
+			if err != nil {
+				return nil, err
+			}
+			c1 := cur
+			if hasLastCubic {
+				c1 = point{2*cur.x - lastCtrl.x, 2*cur.y - lastCtrl.y}
+			}
+			c2 := point{base.x + v[0], base.y + v[1]}
+			cur = point{base.x + v[2], base.y + v[3]}
+			out = append(out, pathSeg{op: 'C', pts: [3]point{c1, c2, cur}})
+			lastCtrl = c2
+		case 'Q', 'q':
+			v, err := ps.numbers(4)
+			if err != nil {
+				return nil, err
+			}
+			q := point{base.x + v[0], base.y + v[1]}
+			end := point{base.x + v[2], base.y + v[3]}
+			out = append(out, quadToCubic(cur, q, end))
+			cur = end
+			lastCtrl = q
+		case 'T', 't':
+			v, err := ps.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			q := cur
+			if hasLastQuad {
+				q = point{2*cur.x - lastCtrl.x, 2*cur.y - lastCtrl.y}
+			}
+			end := point{base.x + v[0], base.y + v[1]}
+			out = append(out, quadToCubic(cur, q, end))
+			cur = end
+			lastCtrl = q
+		case 'A', 'a':
+			v, err := ps.numbers(3)
+			if err != nil {
+				return nil, err
+			}
+			large, err := ps.flag()
+			if err != nil {
+				return nil, err
+			}
+			sweep, err := ps.flag()
+			if err != nil {
+				return nil, err
+			}
+			e, err := ps.numbers(2)
+			if err != nil {
+				return nil, err
+			}
+			end := point{base.x + e[0], base.y + e[1]}
+			out = append(out, arcToCubics(cur, end, v[0], v[1], v[2], large, sweep)...)
+			cur = end
+		case 'Z', 'z':
+			out = append(out, pathSeg{op: 'Z'})
+			cur = start
+		default:
+			return nil, errors.ErrInvalidSVG
+		}
+
+		upper := cmd &^ 0x20
+		hasLastCubic = upper == 'C' || upper == 'S'
+		hasLastQuad = upper == 'Q' || upper == 'T'
+	}
+
+	return out, nil
+}
+
+// quadToCubic 将二次贝塞尔曲线提升为三次贝塞尔曲线
+func quadToCubic(p0, q, p1 point) pathSeg {
+	c1 := point{p0.x + 2.0/3.0*(q.x-p0.x), p0.y + 2.0/3.0*(q.y-p0.y)}
+	c2 := point{p1.x + 2.0/3.0*(q.x-p1.x), p1.y + 2.0/3.0*(q.y-p1.y)}
+	return pathSeg{op: 'C', pts: [3]point{c1, c2, p1}}
+}
+
+// arcToCubics 将SVG椭圆弧转换为若干段三次贝塞尔曲线（参考SVG规范附录F.6）
+func arcToCubics(p0, p1 point, rx, ry, angle float64, large, sweep bool) []pathSeg {
+	if p0 == p1 {
+		return nil
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		return []pathSeg{{op: 'L', pts: [3]point{p1}}}
+	}
+
+	phi := angle * math.Pi / 180
+	sinPhi, cosPhi := math.Sincos(phi)
+
+	// 计算中点坐标系下的起点
+	dx2 := (p0.x - p1.x) / 2
+	dy2 := (p0.y - p1.y) / 2
+	x1p := cosPhi*dx2 + sinPhi*dy2
+	y1p := -sinPhi*dx2 + cosPhi*dy2
+
+	// 半径不足时按比例放大
+	lambda := (x1p*x1p)/(rx*rx) + (y1p*y1p)/(ry*ry)
+	if lambda > 1 {
+		s := math.Sqrt(lambda)
+		rx *= s
+		ry *= s
+	}
+
+	// 计算圆心
+	num := rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	den := rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	coef := 0.0
+	if den != 0 && num > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if large == sweep {
+		coef = -coef
+	}
+	cxp := coef * rx * y1p / ry
+	cyp := -coef * ry * x1p / rx
+	cx := cosPhi*cxp - sinPhi*cyp + (p0.x+p1.x)/2
+	cy := sinPhi*cxp + cosPhi*cyp + (p0.y+p1.y)/2
+
+	// 计算起始角和扫过的角度
+	theta1 := vectorAngle(1, 0, (x1p-cxp)/rx, (y1p-cyp)/ry)
+	delta := vectorAngle((x1p-cxp)/rx, (y1p-cyp)/ry, (-x1p-cxp)/rx, (-y1p-cyp)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	// 每段不超过90度
+	n := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	if n < 1 {
+		n = 1
+	}
+	step := delta / float64(n)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+
+	ellipse := func(t float64) (point, point) {
+		sinT, cosT := math.Sincos(t)
+		p := point{
+			x: cx + rx*cosT*cosPhi - ry*sinT*sinPhi,
+			y: cy + rx*cosT*sinPhi + ry*sinT*cosPhi,
+		}
+		d := point{
+			x: -rx*sinT*cosPhi - ry*cosT*sinPhi,
+			y: -rx*sinT*sinPhi + ry*cosT*cosPhi,
+		}
+		return p, d
+	}
+
+	segs := make([]pathSeg, 0, n)
+	t := theta1
+	from, dFrom := ellipse(t)
+	for i := 0; i < n; i++ {
+		to, dTo := ellipse(t + step)
+		if i == n-1 {
+			to = p1
+		}
+		segs = append(segs, pathSeg{op: 'C', pts: [3]point{
+			{from.x + k*dFrom.x, from.y + k*dFrom.y},
+			{to.x - k*dTo.x, to.y - k*dTo.y},
+			to,
+		}})
+		t += step
+		from, dFrom = to, dTo
+	}
+	return segs
+}
+
+// vectorAngle 计算两个向量之间的有向夹角
+func vectorAngle(ux, uy, vx, vy float64) float64 {
+	return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+}
+
+// polyline 表示展平后的一条子路径
+type polyline struct {
+	pts    []point
+	closed bool
+}
+
+// flatten 在设备坐标系中将路径展平为折线
+func (pd pathData) flatten(m matrix) []polyline {
+	var (
+		out  []polyline
+		cur  *polyline
+		last point
+	)
+	for _, seg := range pd {
+		switch seg.op {
+		case 'M':
+			p := m.apply(seg.pts[0])
+			out = append(out, polyline{pts: []point{p}})
+			cur = &out[len(out)-1]
+			last = p
+		case 'L':
+			if cur == nil {
+				out = append(out, polyline{pts: []point{last}})
+				cur = &out[len(out)-1]
+			}
+			p := m.apply(seg.pts[0])
+			cur.pts = append(cur.pts, p)
+			last = p
+		case 'C':
+			if cur == nil {
+				out = append(out, polyline{pts: []point{last}})
+				cur = &out[len(out)-1]
+			}
+			c1, c2, p := m.apply(seg.pts[0]), m.apply(seg.pts[1]), m.apply(seg.pts[2])
+			cur.pts = appendCubic(cur.pts, last, c1, c2, p)
+			last = p
+		case 'Z':
+			if cur != nil {
+				cur.closed = true
+				last = cur.pts[0]
+				// 闭合后的后续命令从子路径起点开始新的折线
+				cur = nil
+			}
+		}
+	}
+	return out
+}
+
+// appendCubic 按控制多边形长度自适应细分三次贝塞尔曲线
+func appendCubic(pts []point, p0, c1, c2, p1 point) []point {
+	l := math.Hypot(c1.x-p0.x, c1.y-p0.y) + math.Hypot(c2.x-c1.x, c2.y-c1.y) + math.Hypot(p1.x-c2.x, p1.y-c2.y)
+	n := int(math.Ceil(l / 3))
+	if n < 1 {
+		n = 1
+	} else if n > 256 {
+		n = 256
+	}
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		mt := 1 - t
+		a := mt * mt * mt
+		b := 3 * mt * mt * t
+		c := 3 * mt * t * t
+		d := t * t * t
+		pts = append(pts, point{
+			x: a*p0.x + b*c1.x + c*c2.x + d*p1.x,
+			y: a*p0.y + b*c1.y + c*c2.y + d*p1.y,
+		})
+	}
+	return pts
+}
+
+// parsePoints 解析polygon/polyline的points属性
+func parsePoints(s string) ([]point, error) {
+	ps := &pathScanner{s: strings.TrimSpace(s)}
+	var pts []point
+	for ps.hasNumber() {
+		v, err := ps.numbers(2)
+		if err != nil {
+			return nil, err
+		}
+		pts = append(pts, point{v[0], v[1]})
+	}
+	return pts, nil
+}
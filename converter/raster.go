@@ -0,0 +1,191 @@
+package converter
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// subSamples 每个像素行的垂直子采样数，用于抗锯齿
+const subSamples = 5
+
+// edge 多边形的一条边，保证 y0 < y1，dir 记录原始方向用于非零环绕规则
+type edge struct {
+	x0, y0, x1, y1 float64
+	dir            int
+}
+
+// rasterizer 基于扫描线的多边形光栅化器，输出覆盖率蒙版
+type rasterizer struct {
+	width, height int
+	edges         []edge
+}
+
+// newRasterizer 创建一个指定画布大小的光栅化器
+func newRasterizer(width, height int) *rasterizer {
+	return &rasterizer{width: width, height: height}
+}
+
+// reset 清空已添加的边以便复用
+func (r *rasterizer) reset() {
+	r.edges = r.edges[:0]
+}
+
+// addPolygon 添加一个闭合多边形
+func (r *rasterizer) addPolygon(pts []point) {
+	if len(pts) < 3 {
+		return
+	}
+	for i := range pts {
+		a := pts[i]
+		b := pts[(i+1)%len(pts)]
+		if a.y == b.y {
+			continue
+		}
+		if a.y < b.y {
+			r.edges = append(r.edges, edge{a.x, a.y, b.x, b.y, 1})
+		} else {
+			r.edges = append(r.edges, edge{b.x, b.y, a.x, a.y, -1})
+		}
+	}
+}
+
+// mask 计算覆盖率蒙版，evenOdd 为 true 时使用奇偶规则，否则使用非零规则
+func (r *rasterizer) mask(evenOdd bool) *image.Alpha {
+	if len(r.edges) == 0 {
+		return nil
+	}
+
+	// 计算边界框并裁剪到画布
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, e := range r.edges {
+		minX = math.Min(minX, math.Min(e.x0, e.x1))
+		maxX = math.Max(maxX, math.Max(e.x0, e.x1))
+		minY = math.Min(minY, e.y0)
+		maxY = math.Max(maxY, e.y1)
+	}
+	bounds := image.Rect(
+		int(math.Floor(minX)), int(math.Floor(minY)),
+		int(math.Ceil(maxX))+1, int(math.Ceil(maxY))+1,
+	).Intersect(image.Rect(0, 0, r.width, r.height))
+	if bounds.Empty() {
+		return nil
+	}
+
+	sort.Slice(r.edges, func(i, j int) bool { return r.edges[i].y0 < r.edges[j].y0 })
+
+	m := image.NewAlpha(bounds)
+	cover := make([]float64, bounds.Dx()+1)
+
+	type crossing struct {
+		x   float64
+		dir int
+	}
+	var (
+		active    []edge
+		crossings []crossing
+		next      int
+	)
+	weight := 1.0 / subSamples
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for i := range cover {
+			cover[i] = 0
+		}
+		touched := false
+
+		for s := 0; s < subSamples; s++ {
+			sy := float64(y) + (float64(s)+0.5)/subSamples
+
+			// 更新活动边表
+			for next < len(r.edges) && r.edges[next].y0 <= sy {
+				active = append(active, r.edges[next])
+				next++
+			}
+			n := 0
+			for _, e := range active {
+				if e.y1 > sy {
+					active[n] = e
+					n++
+				}
+			}
+			active = active[:n]
+
+			crossings = crossings[:0]
+			for _, e := range active {
+				if e.y0 > sy {
+					continue
+				}
+				x := e.x0 + (sy-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+				crossings = append(crossings, crossing{x, e.dir})
+			}
+			if len(crossings) < 2 {
+				continue
+			}
+			sort.Slice(crossings, func(i, j int) bool { return crossings[i].x < crossings[j].x })
+
+			// 沿扫描线累计环绕数，得到内部区间
+			winding := 0
+			for i := 0; i < len(crossings)-1; i++ {
+				if evenOdd {
+					winding ^= 1
+				} else {
+					winding += crossings[i].dir
+				}
+				if winding == 0 {
+					continue
+				}
+				r.accumulate(cover, bounds.Min.X, crossings[i].x, crossings[i+1].x, weight)
+				touched = true
+			}
+		}
+
+		if !touched {
+			continue
+		}
+		row := m.Pix[(y-bounds.Min.Y)*m.Stride:]
+		for x := 0; x < bounds.Dx(); x++ {
+			c := cover[x]
+			if c <= 0 {
+				continue
+			}
+			if c > 1 {
+				c = 1
+			}
+			row[x] = uint8(c*255 + 0.5)
+		}
+	}
+
+	return m
+}
+
+// accumulate 将区间 [xa, xb) 的覆盖率按像素累加到当前行，区间端点按面积计入小数部分
+func (r *rasterizer) accumulate(cover []float64, originX int, xa, xb, weight float64) {
+	xa -= float64(originX)
+	xb -= float64(originX)
+	limit := float64(len(cover) - 1)
+	if xa < 0 {
+		xa = 0
+	}
+	if xb > limit {
+		xb = limit
+	}
+	if xb <= xa {
+		return
+	}
+
+	ia := int(xa)
+	ib := int(xb)
+	if ia == ib {
+		cover[ia] += (xb - xa) * weight
+		return
+	}
+	cover[ia] += (float64(ia+1) - xa) * weight
+	for i := ia + 1; i < ib; i++ {
+		cover[i] += weight
+	}
+	if ib < len(cover) {
+		cover[ib] += (xb - float64(ib)) * weight
+	}
+}
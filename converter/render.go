@@ -0,0 +1,814 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+// defaultViewBoxSize 内置形状使用的画布大小
+const defaultViewBoxSize = 231
+
+// MaxImageSize 栅格化输出的最大宽高（像素），超出时返回 ErrInvalidSize
+const MaxImageSize = 8192
+
+// paint 表示填充或描边所使用的颜色
+type paint struct {
+	none  bool
+	color color.NRGBA
+}
+
+// drawState 绘制状态，随元素嵌套继承
+type drawState struct {
+	transform     matrix
+	fill          paint
+	stroke        paint
+	strokeWidth   float64
+	fillOpacity   float64
+	strokeOpacity float64
+	opacity       float64
+	fillRule      string
+	lineCap       string
+	lineJoin      string
+	miterLimit    float64
+	hidden        bool
+	clip          *image.Alpha // 当前生效的裁剪蒙版（设备像素），nil表示不裁剪
+}
+
+// defaultDrawState 返回SVG规范定义的初始绘制状态
+func defaultDrawState() drawState {
+	return drawState{
+		transform:     identityMatrix,
+		fill:          paint{color: color.NRGBA{A: 0xff}},
+		stroke:        paint{none: true},
+		strokeWidth:   1,
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		opacity:       1,
+		fillRule:      "nonzero",
+		lineCap:       "butt",
+		lineJoin:      "miter",
+		miterLimit:    4,
+	}
+}
+
+// svgRenderer 将SVG文档绘制到RGBA画布
+type svgRenderer struct {
+	dst       *image.RGBA
+	raster    *rasterizer
+	gradients map[string]paint
+	clipPaths map[string][]clipShape
+}
+
+// clipShape clipPath 中的一个形状
+type clipShape struct {
+	name  string
+	attrs map[string]string
+}
+
+// rasterize 将SVG数据绘制为RGBA图像，像素大小为 width*scale x height*scale，
+// width 或 height 不大于0时使用SVG自身尺寸，像素大小超出 MaxImageSize 时返回错误
+func rasterize(data []byte, width, height int, scale float64) (*image.RGBA, error) {
+	if scale <= 0 || math.IsNaN(scale) {
+		scale = 1
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+
+	r := &svgRenderer{gradients: make(map[string]paint), clipPaths: make(map[string][]clipShape)}
+	r.collectGradients(data)
+	r.collectClipPaths(data)
+
+	stack := []drawState{defaultDrawState()}
+	skipDepth := 0
+	rootSeen := false
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.ErrInvalidSVG
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+			attrs := attrMap(t.Attr)
+			name := t.Name.Local
+
+			if !rootSeen {
+				if name != "svg" {
+					return nil, errors.ErrInvalidSVG
+				}
+				rootSeen = true
+				m, w, h := rootTransform(attrs, width, height, scale)
+				if w > MaxImageSize || h > MaxImageSize {
+					return nil, fmt.Errorf("%w: image exceeds %dx%d pixels", errors.ErrInvalidSize, MaxImageSize, MaxImageSize)
+				}
+				if w <= 0 || h <= 0 {
+					return nil, errors.ErrInvalidSVG
+				}
+				r.dst = image.NewRGBA(image.Rect(0, 0, w, h))
+				r.raster = newRasterizer(w, h)
+				st := r.applyAttributes(stack[0], attrs)
+				st.transform = m
+				stack = append(stack, st)
+				continue
+			}
+
+			switch name {
+			case "defs", "style", "title", "desc", "metadata", "linearGradient", "radialGradient",
+				"clipPath", "mask", "pattern", "symbol", "filter", "marker", "script":
+				skipDepth = 1
+				continue
+			}
+
+			st := r.applyAttributes(stack[len(stack)-1], attrs)
+			stack = append(stack, st)
+			if !st.hidden {
+				if err := r.drawElement(name, attrs, st); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if r.dst == nil {
+		return nil, errors.ErrInvalidSVG
+	}
+	return r.dst, nil
+}
+
+// collectGradients 预先收集渐变定义，栅格化时以首个色标作为纯色近似
+func (r *svgRenderer) collectGradients(data []byte) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	current := ""
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			attrs := attrMap(t.Attr)
+			switch t.Name.Local {
+			case "linearGradient", "radialGradient":
+				current = attrs["id"]
+			case "stop":
+				if current == "" {
+					continue
+				}
+				if _, ok := r.gradients[current]; ok {
+					continue
+				}
+				props := styleProps(attrs)
+				if c, ok := parseColor(props["stop-color"]); ok {
+					if op, err := strconv.ParseFloat(props["stop-opacity"], 64); err == nil {
+						c.A = uint8(float64(c.A) * clamp01(op))
+					}
+					r.gradients[current] = paint{color: c}
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "linearGradient" || t.Name.Local == "radialGradient" {
+				current = ""
+			}
+		}
+	}
+}
+
+// collectClipPaths 收集文档中的 clipPath 定义
+func (r *svgRenderer) collectClipPaths(data []byte) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	current := ""
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			attrs := attrMap(t.Attr)
+			if t.Name.Local == "clipPath" {
+				current = attrs["id"]
+				continue
+			}
+			if current != "" {
+				r.clipPaths[current] = append(r.clipPaths[current], clipShape{name: t.Name.Local, attrs: attrs})
+			}
+		case xml.EndElement:
+			if t.Name.Local == "clipPath" {
+				current = ""
+			}
+		}
+	}
+}
+
+// clipMask 计算 clipPath 在设备像素上的覆盖率，并与父级裁剪蒙版相交
+func (r *svgRenderer) clipMask(id string, m matrix, parent *image.Alpha) *image.Alpha {
+	b := r.dst.Bounds()
+	out := image.NewAlpha(b)
+	r.raster.reset()
+	for _, shape := range r.clipPaths[id] {
+		sm := m
+		if tf, ok := shape.attrs["transform"]; ok {
+			sm = m.mul(parseTransform(tf))
+		}
+		pd, _, err := elementPath(shape.name, shape.attrs)
+		if err != nil {
+			continue
+		}
+		for _, l := range pd.flatten(sm) {
+			r.raster.addPolygon(l.pts)
+		}
+	}
+	if cov := r.raster.mask(false); cov != nil {
+		draw.Draw(out, cov.Rect, cov, cov.Rect.Min, draw.Src)
+	}
+	if parent != nil {
+		intersectAlpha(out, parent)
+	}
+	return out
+}
+
+// intersectAlpha 将蒙版与另一个蒙版逐像素相乘
+func intersectAlpha(dst, other *image.Alpha) {
+	b := dst.Rect
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := dst.PixOffset(x, y)
+			if dst.Pix[i] == 0 {
+				continue
+			}
+			dst.Pix[i] = uint8((uint32(dst.Pix[i])*uint32(other.AlphaAt(x, y).A) + 127) / 255)
+		}
+	}
+}
+
+// drawElement 绘制一个基本形状元素
+func (r *svgRenderer) drawElement(name string, attrs map[string]string, st drawState) error {
+	pd, isLine, err := elementPath(name, attrs)
+	if err != nil {
+		return err
+	}
+	if len(pd) == 0 {
+		return nil
+	}
+	lines := pd.flatten(st.transform)
+
+	// 填充
+	if !isLine && !st.fill.none {
+		r.raster.reset()
+		for _, l := range lines {
+			r.raster.addPolygon(l.pts)
+		}
+		r.paint(st.fill, st.fillOpacity*st.opacity, st.fillRule == "evenodd", st.clip)
+	}
+
+	// 描边
+	if !st.stroke.none && st.strokeWidth > 0 {
+		polys := strokePolygons(lines, strokeStyle{
+			width:      st.strokeWidth * st.transform.scale(),
+			lineCap:    st.lineCap,
+			lineJoin:   st.lineJoin,
+			miterLimit: st.miterLimit,
+		})
+		r.raster.reset()
+		for _, p := range polys {
+			r.raster.addPolygon(p)
+		}
+		r.paint(st.stroke, st.strokeOpacity*st.opacity, false, st.clip)
+	}
+
+	return nil
+}
+
+// paint 以蒙版方式将颜色合成到画布，clip 不为nil时只绘制裁剪区域内的部分
+func (r *svgRenderer) paint(p paint, opacity float64, evenOdd bool, clip *image.Alpha) {
+	m := r.raster.mask(evenOdd)
+	if m == nil {
+		return
+	}
+	if clip != nil {
+		intersectAlpha(m, clip)
+	}
+	c := p.color
+	c.A = uint8(float64(c.A)*clamp01(opacity) + 0.5)
+	if c.A == 0 {
+		return
+	}
+	draw.DrawMask(r.dst, m.Rect, image.NewUniform(c), image.Point{}, m, m.Rect.Min, draw.Over)
+}
+
+// elementPath 将基本形状元素转换为路径，isLine 表示该形状只有描边没有填充
+func elementPath(name string, attrs map[string]string) (pd pathData, isLine bool, err error) {
+	switch name {
+	case "path":
+		pd, err = parsePathData(attrs["d"])
+		if err != nil {
+			return nil, false, err
+		}
+	case "rect":
+		pd = rectPath(length(attrs["x"]), length(attrs["y"]), length(attrs["width"]), length(attrs["height"]), attrs["rx"], attrs["ry"])
+	case "circle":
+		rad := length(attrs["r"])
+		pd = ellipsePath(length(attrs["cx"]), length(attrs["cy"]), rad, rad)
+	case "ellipse":
+		pd = ellipsePath(length(attrs["cx"]), length(attrs["cy"]), length(attrs["rx"]), length(attrs["ry"]))
+	case "line":
+		pd = pathData{
+			{op: 'M', pts: [3]point{{length(attrs["x1"]), length(attrs["y1"])}}},
+			{op: 'L', pts: [3]point{{length(attrs["x2"]), length(attrs["y2"])}}},
+		}
+		isLine = true
+	case "polygon", "polyline":
+		pts, err := parsePoints(attrs["points"])
+		if err != nil {
+			return nil, false, err
+		}
+		for i, p := range pts {
+			op := byte('L')
+			if i == 0 {
+				op = 'M'
+			}
+			pd = append(pd, pathSeg{op: op, pts: [3]point{p}})
+		}
+		if name == "polygon" && len(pd) > 0 {
+			pd = append(pd, pathSeg{op: 'Z'})
+		}
+	}
+	return pd, isLine, nil
+}
+
+// pixels 将尺寸取整为像素数，超出 MaxImageSize 的值统一为 MaxImageSize+1，避免转换溢出
+func pixels(size float64) int {
+	if size > MaxImageSize {
+		return MaxImageSize + 1
+	}
+	return int(math.Round(size))
+}
+
+// rootTransform 根据根元素的 viewBox、width、height 与目标像素大小计算初始变换
+func rootTransform(attrs map[string]string, width, height int, scale float64) (matrix, int, int) {
+	vx, vy, vw, vh := 0.0, 0.0, 0.0, 0.0
+	if vb := strings.Fields(strings.ReplaceAll(attrs["viewBox"], ",", " ")); len(vb) == 4 {
+		vx, vy, vw, vh = length(vb[0]), length(vb[1]), length(vb[2]), length(vb[3])
+	}
+	aw, ah := length(attrs["width"]), length(attrs["height"])
+	if vw <= 0 || vh <= 0 {
+		vw, vh = aw, ah
+		if vw <= 0 || vh <= 0 {
+			vw, vh = defaultViewBoxSize, defaultViewBoxSize
+		}
+	}
+
+	// 目标尺寸优先使用调用方指定值，其次是width/height属性，最后是viewBox
+	fw, fh := float64(width), float64(height)
+	if width <= 0 || height <= 0 {
+		if aw > 0 && ah > 0 {
+			fw, fh = aw, ah
+		} else {
+			fw, fh = vw, vh
+		}
+	}
+	width, height = pixels(fw*scale), pixels(fh*scale)
+
+	sx := float64(width) / vw
+	sy := float64(height) / vh
+	tx, ty := 0.0, 0.0
+
+	par := strings.Fields(attrs["preserveAspectRatio"])
+	align, meetOrSlice := "xMidYMid", "meet"
+	if len(par) > 0 {
+		align = par[0]
+	}
+	if len(par) > 1 {
+		meetOrSlice = par[1]
+	}
+	if align != "none" {
+		s := math.Min(sx, sy)
+		if meetOrSlice == "slice" {
+			s = math.Max(sx, sy)
+		}
+		sx, sy = s, s
+		extraX := float64(width) - vw*s
+		extraY := float64(height) - vh*s
+		switch {
+		case strings.Contains(align, "xMid"):
+			tx = extraX / 2
+		case strings.Contains(align, "xMax"):
+			tx = extraX
+		}
+		switch {
+		case strings.Contains(align, "YMid"):
+			ty = extraY / 2
+		case strings.Contains(align, "YMax"):
+			ty = extraY
+		}
+	}
+
+	return matrix{sx, 0, 0, sy, tx - vx*sx, ty - vy*sy}, width, height
+}
+
+// applyAttributes 根据元素的表现属性和style属性计算新的绘制状态
+func (r *svgRenderer) applyAttributes(parent drawState, attrs map[string]string) drawState {
+	st := parent
+	// opacity 不继承，由父级乘入
+	st.opacity = parent.opacity
+
+	if tf, ok := attrs["transform"]; ok {
+		st.transform = parent.transform.mul(parseTransform(tf))
+	}
+
+	for k, v := range styleProps(attrs) {
+		v = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(v), "!important"))
+		switch k {
+		case "fill":
+			st.fill = r.parsePaint(v, parent.fill)
+		case "stroke":
+			st.stroke = r.parsePaint(v, parent.stroke)
+		case "stroke-width":
+			st.strokeWidth = length(v)
+		case "fill-opacity":
+			st.fillOpacity = parseOpacity(v)
+		case "stroke-opacity":
+			st.strokeOpacity = parseOpacity(v)
+		case "opacity":
+			st.opacity = parent.opacity * parseOpacity(v)
+		case "fill-rule":
+			st.fillRule = v
+		case "stroke-linecap":
+			st.lineCap = v
+		case "stroke-linejoin":
+			st.lineJoin = v
+		case "stroke-miterlimit":
+			if ml := length(v); ml >= 1 {
+				st.miterLimit = ml
+			}
+		case "display":
+			if v == "none" {
+				st.hidden = true
+			}
+		case "visibility":
+			st.hidden = v == "hidden" || v == "collapse"
+		case "clip-path":
+			if id, ok := urlRef(v); ok && r.dst != nil {
+				st.clip = r.clipMask(id, st.transform, parent.clip)
+			}
+		}
+	}
+	return st
+}
+
+// styleProps 合并表现属性与style属性，style属性优先
+func styleProps(attrs map[string]string) map[string]string {
+	props := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		props[k] = v
+	}
+	if s, ok := attrs["style"]; ok {
+		for _, decl := range strings.Split(s, ";") {
+			kv := strings.SplitN(decl, ":", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			props[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	return props
+}
+
+// attrMap 将XML属性转换为映射
+func attrMap(attrs []xml.Attr) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		m[a.Name.Local] = a.Value
+	}
+	return m
+}
+
+// parsePaint 解析fill/stroke的取值，不可识别时沿用继承值
+func (r *svgRenderer) parsePaint(v string, inherited paint) paint {
+	v = strings.TrimSpace(v)
+	switch strings.ToLower(v) {
+	case "", "inherit":
+		return inherited
+	case "none", "transparent", "#none":
+		return paint{none: true}
+	case "currentcolor":
+		return paint{color: color.NRGBA{A: 0xff}}
+	}
+	if strings.HasPrefix(v, "url(") {
+		// 引用渐变时使用预先收集的近似颜色，否则使用回退值
+		end := strings.Index(v, ")")
+		if end > 0 {
+			id := strings.TrimPrefix(strings.TrimSpace(v[4:end]), "#")
+			if p, ok := r.gradients[id]; ok {
+				return p
+			}
+			if fallback := strings.TrimSpace(v[end+1:]); fallback != "" {
+				return r.parsePaint(fallback, inherited)
+			}
+		}
+		return inherited
+	}
+	if c, ok := parseColor(v); ok {
+		return paint{color: c}
+	}
+	return inherited
+}
+
+// urlRef 解析 url(#id) 引用
+func urlRef(v string) (string, bool) {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "url(") || !strings.HasSuffix(v, ")") {
+		return "", false
+	}
+	id := strings.Trim(strings.TrimSpace(v[4:len(v)-1]), `'"`)
+	if !strings.HasPrefix(id, "#") {
+		return "", false
+	}
+	return id[1:], true
+}
+
+// parseColor 解析CSS颜色，支持 #rgb、#rgba、#rrggbb、#rrggbbaa、rgb()、rgba() 和常用颜色名
+func parseColor(v string) (color.NRGBA, bool) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if strings.HasPrefix(v, "#") {
+		hex := v[1:]
+		switch len(hex) {
+		case 3, 4:
+			var c [4]uint8
+			c[3] = 0xff
+			for i := 0; i < len(hex); i++ {
+				n, ok := hexDigit(hex[i])
+				if !ok {
+					return color.NRGBA{}, false
+				}
+				c[i] = n * 17
+			}
+			return color.NRGBA{R: c[0], G: c[1], B: c[2], A: c[3]}, true
+		case 6, 8:
+			n, err := strconv.ParseUint(hex, 16, 32)
+			if err != nil {
+				return color.NRGBA{}, false
+			}
+			if len(hex) == 6 {
+				return color.NRGBA{R: uint8(n >> 16), G: uint8(n >> 8), B: uint8(n), A: 0xff}, true
+			}
+			return color.NRGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, true
+		}
+		return color.NRGBA{}, false
+	}
+	if strings.HasPrefix(v, "rgb") {
+		start := strings.Index(v, "(")
+		end := strings.LastIndex(v, ")")
+		if start < 0 || end < start {
+			return color.NRGBA{}, false
+		}
+		parts := strings.FieldsFunc(v[start+1:end], func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+		if len(parts) < 3 {
+			return color.NRGBA{}, false
+		}
+		var c [4]uint8
+		c[3] = 0xff
+		for i := 0; i < 3; i++ {
+			p := parts[i]
+			f, err := strconv.ParseFloat(strings.TrimSuffix(p, "%"), 64)
+			if err != nil {
+				return color.NRGBA{}, false
+			}
+			if strings.HasSuffix(p, "%") {
+				f = f * 255 / 100
+			}
+			c[i] = uint8(math.Max(0, math.Min(255, math.Round(f))))
+		}
+		if len(parts) > 3 {
+			c[3] = uint8(parseOpacity(parts[3])*255 + 0.5)
+		}
+		return color.NRGBA{R: c[0], G: c[1], B: c[2], A: c[3]}, true
+	}
+	if c, ok := namedColors[v]; ok {
+		return c, true
+	}
+	return color.NRGBA{}, false
+}
+
+// namedColors 常用CSS颜色名
+var namedColors = map[string]color.NRGBA{
+	"black":   {0, 0, 0, 0xff},
+	"white":   {0xff, 0xff, 0xff, 0xff},
+	"red":     {0xff, 0, 0, 0xff},
+	"green":   {0, 0x80, 0, 0xff},
+	"blue":    {0, 0, 0xff, 0xff},
+	"yellow":  {0xff, 0xff, 0, 0xff},
+	"orange":  {0xff, 0xa5, 0, 0xff},
+	"purple":  {0x80, 0, 0x80, 0xff},
+	"pink":    {0xff, 0xc0, 0xcb, 0xff},
+	"cyan":    {0, 0xff, 0xff, 0xff},
+	"magenta": {0xff, 0, 0xff, 0xff},
+	"gray":    {0x80, 0x80, 0x80, 0xff},
+	"grey":    {0x80, 0x80, 0x80, 0xff},
+	"silver":  {0xc0, 0xc0, 0xc0, 0xff},
+	"gold":    {0xff, 0xd7, 0, 0xff},
+	"navy":    {0, 0, 0x80, 0xff},
+	"teal":    {0, 0x80, 0x80, 0xff},
+	"maroon":  {0x80, 0, 0, 0xff},
+	"lime":    {0, 0xff, 0, 0xff},
+}
+
+// hexDigit 解析单个十六进制字符
+func hexDigit(c byte) (uint8, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	}
+	return 0, false
+}
+
+// parseOpacity 解析透明度，支持小数和百分比
+func parseOpacity(v string) float64 {
+	v = strings.TrimSpace(v)
+	if strings.HasSuffix(v, "%") {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+		if err != nil {
+			return 1
+		}
+		return clamp01(f / 100)
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 1
+	}
+	return clamp01(f)
+}
+
+// length 解析长度值，忽略 px 单位
+func length(v string) float64 {
+	v = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(v), "px"))
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// clamp01 将数值限制在[0,1]区间
+func clamp01(f float64) float64 {
+	return math.Max(0, math.Min(1, f))
+}
+
+// parseTransform 解析transform属性，支持 matrix、translate、scale、rotate、skewX、skewY
+func parseTransform(s string) matrix {
+	m := identityMatrix
+	for {
+		s = strings.TrimLeft(s, " \t\n\r,")
+		open := strings.Index(s, "(")
+		if open < 0 {
+			break
+		}
+		end := strings.Index(s[open:], ")")
+		if end < 0 {
+			break
+		}
+		name := strings.TrimSpace(s[:open])
+		ps := &pathScanner{s: s[open+1 : open+end]}
+		var vals []float64
+		for ps.hasNumber() {
+			v, err := ps.number()
+			if err != nil {
+				break
+			}
+			vals = append(vals, v)
+		}
+		s = s[open+end+1:]
+
+		var t matrix
+		switch name {
+		case "matrix":
+			if len(vals) != 6 {
+				continue
+			}
+			t = matrix{vals[0], vals[1], vals[2], vals[3], vals[4], vals[5]}
+		case "translate":
+			if len(vals) == 0 {
+				continue
+			}
+			ty := 0.0
+			if len(vals) > 1 {
+				ty = vals[1]
+			}
+			t = matrix{1, 0, 0, 1, vals[0], ty}
+		case "scale":
+			if len(vals) == 0 {
+				continue
+			}
+			sy := vals[0]
+			if len(vals) > 1 {
+				sy = vals[1]
+			}
+			t = matrix{vals[0], 0, 0, sy, 0, 0}
+		case "rotate":
+			if len(vals) == 0 {
+				continue
+			}
+			sin, cos := math.Sincos(vals[0] * math.Pi / 180)
+			t = matrix{cos, sin, -sin, cos, 0, 0}
+			if len(vals) == 3 {
+				cx, cy := vals[1], vals[2]
+				t = matrix{1, 0, 0, 1, cx, cy}.mul(t).mul(matrix{1, 0, 0, 1, -cx, -cy})
+			}
+		case "skewX":
+			if len(vals) == 0 {
+				continue
+			}
+			t = matrix{1, 0, math.Tan(vals[0] * math.Pi / 180), 1, 0, 0}
+		case "skewY":
+			if len(vals) == 0 {
+				continue
+			}
+			t = matrix{1, math.Tan(vals[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			continue
+		}
+		m = m.mul(t)
+	}
+	return m
+}
+
+// rectPath 构造矩形（可带圆角）的路径
+func rectPath(x, y, w, h float64, rxAttr, ryAttr string) pathData {
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+	rx, ry := length(rxAttr), length(ryAttr)
+	if rxAttr == "" {
+		rx = ry
+	}
+	if ryAttr == "" {
+		ry = rx
+	}
+	rx = math.Min(math.Abs(rx), w/2)
+	ry = math.Min(math.Abs(ry), h/2)
+
+	if rx == 0 || ry == 0 {
+		return pathData{
+			{op: 'M', pts: [3]point{{x, y}}},
+			{op: 'L', pts: [3]point{{x + w, y}}},
+			{op: 'L', pts: [3]point{{x + w, y + h}}},
+			{op: 'L', pts: [3]point{{x, y + h}}},
+			{op: 'Z'},
+		}
+	}
+
+	pd := pathData{{op: 'M', pts: [3]point{{x + rx, y}}}}
+	pd = append(pd, pathSeg{op: 'L', pts: [3]point{{x + w - rx, y}}})
+	pd = append(pd, arcToCubics(point{x + w - rx, y}, point{x + w, y + ry}, rx, ry, 0, false, true)...)
+	pd = append(pd, pathSeg{op: 'L', pts: [3]point{{x + w, y + h - ry}}})
+	pd = append(pd, arcToCubics(point{x + w, y + h - ry}, point{x + w - rx, y + h}, rx, ry, 0, false, true)...)
+	pd = append(pd, pathSeg{op: 'L', pts: [3]point{{x + rx, y + h}}})
+	pd = append(pd, arcToCubics(point{x + rx, y + h}, point{x, y + h - ry}, rx, ry, 0, false, true)...)
+	pd = append(pd, pathSeg{op: 'L', pts: [3]point{{x, y + ry}}})
+	pd = append(pd, arcToCubics(point{x, y + ry}, point{x + rx, y}, rx, ry, 0, false, true)...)
+	pd = append(pd, pathSeg{op: 'Z'})
+	return pd
+}
+
+// ellipsePath 构造椭圆路径
+func ellipsePath(cx, cy, rx, ry float64) pathData {
+	if rx <= 0 || ry <= 0 {
+		return nil
+	}
+	pd := pathData{{op: 'M', pts: [3]point{{cx + rx, cy}}}}
+	pd = append(pd, arcToCubics(point{cx + rx, cy}, point{cx - rx, cy}, rx, ry, 0, false, true)...)
+	pd = append(pd, arcToCubics(point{cx - rx, cy}, point{cx + rx, cy}, rx, ry, 0, false, true)...)
+	pd = append(pd, pathSeg{op: 'Z'})
+	return pd
+}
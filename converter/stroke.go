@@ -0,0 +1,183 @@
+package converter
+
+import "math"
+
+// strokeStyle 描边参数
+type strokeStyle struct {
+	width      float64 // 设备坐标系下的描边宽度
+	lineCap    string  // butt、round 或 square
+	lineJoin   string  // miter、round 或 bevel
+	miterLimit float64 // 斜接长度上限
+}
+
+// strokePolygons 将折线描边展开为一组同向多边形，配合非零规则即可得到描边区域的并集
+func strokePolygons(lines []polyline, st strokeStyle) [][]point {
+	hw := st.width / 2
+	if hw <= 0 {
+		return nil
+	}
+
+	var polys [][]point
+	add := func(pts []point) {
+		polys = append(polys, orientPositive(pts))
+	}
+
+	for _, l := range lines {
+		pts := dedupe(l.pts)
+		if l.closed && len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+			pts = pts[:len(pts)-1]
+		}
+
+		// 退化为一个点时只有圆头和方头可见
+		if len(pts) == 1 {
+			switch st.lineCap {
+			case "round":
+				add(circlePolygon(pts[0], hw))
+			case "square":
+				p := pts[0]
+				add([]point{{p.x - hw, p.y - hw}, {p.x + hw, p.y - hw}, {p.x + hw, p.y + hw}, {p.x - hw, p.y + hw}})
+			}
+			continue
+		}
+
+		n := len(pts)
+		segCount := n - 1
+		if l.closed {
+			segCount = n
+		}
+
+		// 每条线段生成一个矩形
+		for i := 0; i < segCount; i++ {
+			a := pts[i]
+			b := pts[(i+1)%n]
+			nx, ny := normal(a, b)
+			if !l.closed && st.lineCap == "square" {
+				dx, dy := ny, -nx
+				if i == 0 {
+					a = point{a.x - dx*hw, a.y - dy*hw}
+				}
+				if i == segCount-1 {
+					b = point{b.x + dx*hw, b.y + dy*hw}
+				}
+			}
+			add([]point{
+				{a.x + nx*hw, a.y + ny*hw},
+				{b.x + nx*hw, b.y + ny*hw},
+				{b.x - nx*hw, b.y - ny*hw},
+				{a.x - nx*hw, a.y - ny*hw},
+			})
+		}
+
+		// 连接处
+		for i := 0; i < n; i++ {
+			if !l.closed && (i == 0 || i == n-1) {
+				continue
+			}
+			prev := pts[(i-1+n)%n]
+			cur := pts[i]
+			next := pts[(i+1)%n]
+			add(joinPolygon(prev, cur, next, hw, st))
+		}
+
+		// 线帽
+		if !l.closed && st.lineCap == "round" {
+			add(circlePolygon(pts[0], hw))
+			add(circlePolygon(pts[n-1], hw))
+		}
+	}
+
+	return polys
+}
+
+// joinPolygon 生成两条线段连接处的填充多边形
+func joinPolygon(prev, cur, next point, hw float64, st strokeStyle) []point {
+	if st.lineJoin == "round" {
+		return circlePolygon(cur, hw)
+	}
+
+	n1x, n1y := normal(prev, cur)
+	n2x, n2y := normal(cur, next)
+
+	// 根据转向确定外侧
+	cross := (cur.x-prev.x)*(next.y-cur.y) - (cur.y-prev.y)*(next.x-cur.x)
+	sign := 1.0
+	if cross > 0 {
+		sign = -1
+	}
+	o1 := point{cur.x + sign*n1x*hw, cur.y + sign*n1y*hw}
+	o2 := point{cur.x + sign*n2x*hw, cur.y + sign*n2y*hw}
+
+	if st.lineJoin != "bevel" {
+		// 斜接：计算两条外侧偏移线的交点
+		cosTheta := n1x*n2x + n1y*n2y
+		if 1+cosTheta > 1e-9 {
+			miterLen := 1 / math.Sqrt((1+cosTheta)/2)
+			if miterLen <= st.miterLimit {
+				mx, my := n1x+n2x, n1y+n2y
+				ml := math.Hypot(mx, my)
+				if ml > 0 {
+					tip := point{cur.x + sign*mx/ml*hw*miterLen, cur.y + sign*my/ml*hw*miterLen}
+					return []point{cur, o1, tip, o2}
+				}
+			}
+		}
+	}
+	return []point{cur, o1, o2}
+}
+
+// normal 返回线段 a->b 的单位法向量
+func normal(a, b point) (float64, float64) {
+	dx, dy := b.x-a.x, b.y-a.y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return 0, 0
+	}
+	return -dy / l, dx / l
+}
+
+// circlePolygon 用多边形近似圆形
+func circlePolygon(c point, r float64) []point {
+	n := int(math.Ceil(r * 2))
+	if n < 8 {
+		n = 8
+	} else if n > 128 {
+		n = 128
+	}
+	pts := make([]point, n)
+	for i := range pts {
+		s, co := math.Sincos(2 * math.Pi * float64(i) / float64(n))
+		pts[i] = point{c.x + r*co, c.y + r*s}
+	}
+	return pts
+}
+
+// orientPositive 保证多边形为正向（有向面积为正）
+func orientPositive(pts []point) []point {
+	area := 0.0
+	for i := range pts {
+		a := pts[i]
+		b := pts[(i+1)%len(pts)]
+		area += a.x*b.y - b.x*a.y
+	}
+	if area < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+	return pts
+}
+
+// dedupe 去除连续重复的点
+func dedupe(pts []point) []point {
+	if len(pts) < 2 {
+		return pts
+	}
+	out := make([]point, 1, len(pts))
+	out[0] = pts[0]
+	for _, p := range pts[1:] {
+		if p != out[len(out)-1] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -12,4 +12,31 @@ var (
 	ErrInvalidColor         = errors.New("pixelnebula: invalid color scheme")
 	ErrInsufficientHash     = errors.New("pixelnebula: insufficient hash digits generated")
 	ErrInvalidStyleName     = errors.New("pixelnebula: invalid style name")
+	ErrInvalidSVG           = errors.New("pixelnebula: invalid svg data")
+	ErrNoFrames             = errors.New("pixelnebula: no frames to encode")
+	ErrInvalidFrameSize     = errors.New("pixelnebula: frames must have the same size")
+	ErrInvalidIDPrefix      = errors.New("pixelnebula: invalid id prefix")
+	ErrInvalidSize          = errors.New("pixelnebula: invalid size or padding")
+	ErrInvalidAspectRatio   = errors.New("pixelnebula: invalid preserveAspectRatio value")
+	ErrInvalidMaskShape     = errors.New("pixelnebula: invalid mask shape")
+	ErrInvalidBackground    = errors.New("pixelnebula: invalid background mode or color")
+	ErrInvalidCacheOptions  = errors.New("pixelnebula: invalid cache options")
+	ErrInvalidAnimation     = errors.New("pixelnebula: invalid animation")
+	ErrInvalidOption        = errors.New("pixelnebula: invalid option")
+	ErrInvalidSpec          = errors.New("pixelnebula: invalid avatar spec")
 )
+
+// Join 将多个错误合并为一个错误，nil 会被忽略，全部为 nil 时返回 nil
+func Join(errs ...error) error {
+	return errors.Join(errs...)
+}
+
+// Is 判断错误链中是否包含目标错误，等同于标准库的 errors.Is
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As 在错误链中查找与target类型匹配的错误，等同于标准库的 errors.As
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
@@ -0,0 +1,64 @@
+package errors
+
+import "fmt"
+
+// IndexKind 越界索引的种类
+type IndexKind string
+
+// 预定义索引种类常量
+const (
+	IndexStyle    IndexKind = "style"     // 风格索引
+	IndexTheme    IndexKind = "theme"     // 主题索引
+	IndexPart     IndexKind = "part"      // 主题中的部分索引
+	IndexShapeSet IndexKind = "shape set" // 形状集合索引
+)
+
+// indexSentinels 各种类索引对应的错误常量
+var indexSentinels = map[IndexKind]error{
+	IndexStyle:    ErrInvalidStyleName,
+	IndexTheme:    ErrInvalidTheme,
+	IndexPart:     ErrInvalidPart,
+	IndexShapeSet: ErrInvalidShapeSetIndex,
+}
+
+// IndexError 索引越界错误，可以用 errors.Is 匹配对应的错误常量
+type IndexError struct {
+	Kind  IndexKind // 索引种类
+	Got   int       // 传入的索引
+	Min   int       // 有效范围的最小值
+	Max   int       // 有效范围的最大值（包含），小于 Min 表示没有可用的索引
+	Style int       // 索引所属的风格，不适用时为 -1
+}
+
+// Error 实现 error 接口
+func (e *IndexError) Error() string {
+	msg := fmt.Sprintf("pixelnebula: %s index %d out of range [%d, %d]", e.Kind, e.Got, e.Min, e.Max)
+	if e.Max < e.Min {
+		msg = fmt.Sprintf("pixelnebula: %s index %d out of range (none available)", e.Kind, e.Got)
+	}
+	if e.Style >= 0 {
+		msg += fmt.Sprintf(" for style %d", e.Style)
+	}
+	return msg
+}
+
+// Is 匹配索引种类对应的错误常量
+func (e *IndexError) Is(target error) bool {
+	return target != nil && indexSentinels[e.Kind] == target
+}
+
+// ShapeError 风格中缺少某个部分的形状，可以用 errors.Is 匹配 ErrInvalidShapeType
+type ShapeError struct {
+	Style int    // 风格索引
+	Part  string // 缺少的部分，如 "eyes"
+}
+
+// Error 实现 error 接口
+func (e *ShapeError) Error() string {
+	return fmt.Sprintf("pixelnebula: style %d has no %q shape", e.Style, e.Part)
+}
+
+// Is 匹配 ErrInvalidShapeType
+func (e *ShapeError) Is(target error) bool {
+	return target == ErrInvalidShapeType
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"image/color"
 	"os"
 
 	"github.com/landaiqing/go-pixelnebula"
@@ -51,5 +52,35 @@ func main() {
 			fmt.Println("成功生成Base64编码文件: format_conversion.base64.txt")
 		}
 	}
+
+	// 3. 转换为PNG格式（纯Go栅格化，动画会被忽略）
+	pngData, err := pn.Generate("format-conversion", false).ToPNG()
+	if err != nil {
+		fmt.Printf("转换为PNG失败: %v\n", err)
+	} else if err = os.WriteFile("format_conversion.png", pngData, 0644); err != nil {
+		fmt.Printf("保存PNG文件失败: %v\n", err)
+	} else {
+		fmt.Println("成功生成PNG文件: format_conversion.png")
+	}
+
+	// 4. 转换为JPEG格式（JPEG不支持透明，使用白色背景）
+	jpegData, err := pn.Generate("format-conversion", false).ToJPEG(color.White)
+	if err != nil {
+		fmt.Printf("转换为JPEG失败: %v\n", err)
+	} else if err = os.WriteFile("format_conversion.jpg", jpegData, 0644); err != nil {
+		fmt.Printf("保存JPEG文件失败: %v\n", err)
+	} else {
+		fmt.Println("成功生成JPEG文件: format_conversion.jpg")
+	}
+
+	// 5. 按动画时间轴采样导出为GIF动画
+	gifData, err := pn.Generate("format-conversion", false).SetSize(200, 200).ToGIF(&pixelnebula.FrameOptions{Frames: 30})
+	if err != nil {
+		fmt.Printf("转换为GIF失败: %v\n", err)
+	} else if err = os.WriteFile("format_conversion.gif", gifData, 0644); err != nil {
+		fmt.Printf("保存GIF文件失败: %v\n", err)
+	} else {
+		fmt.Println("成功生成GIF文件: format_conversion.gif")
+	}
 	fmt.Println("格式转换示例完成！")
 }
@@ -0,0 +1,234 @@
+package pixelnebula
+
+import (
+	"image"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/landaiqing/go-pixelnebula/animation"
+	"github.com/landaiqing/go-pixelnebula/converter"
+)
+
+// DefaultFrameCount 导出动画时默认的帧数
+const DefaultFrameCount = 24
+
+// FrameOptions 动画导出选项
+type FrameOptions struct {
+	Frames    int     // 帧数，默认 DefaultFrameCount
+	Duration  float64 // 时间轴长度（秒），0 表示使用最长动画的一次迭代
+	Scale     float64 // DPI缩放系数，默认1
+	LoopCount int     // 播放次数，0 表示无限循环
+}
+
+// animationElements SMIL动画元素，生成静态快照时会被移除
+var animationElements = map[string]bool{
+	"animate":          true,
+	"animateTransform": true,
+	"animateMotion":    true,
+	"animateColor":     true,
+	"set":              true,
+}
+
+// snapshotSVG 计算所有动画在时刻t的效果，生成不含SMIL及CSS动画的静态SVG
+// idPrefix 为SVG中元素ID的前缀，需与动画的目标ID拼接后查找元素
+func snapshotSVG(svg string, anims []animation.Animation, t float64, idPrefix string) (string, error) {
+	root, err := parseSVGDOM(svg)
+	if err != nil {
+		return "", err
+	}
+	root.removeChildren(func(n *svgNode) bool {
+		return animationElements[n.name] || n.name == "style" && strings.Contains(n.innerText(), "@keyframes")
+	})
+
+	for _, effect := range animation.SampleAll(anims, t) {
+		target, parent := root.findByID(idPrefix + effect.TargetID)
+		if target == nil {
+			continue
+		}
+
+		props := make([]string, 0, len(effect.Attributes))
+		for prop := range effect.Attributes {
+			props = append(props, prop)
+		}
+		sort.Strings(props)
+		for _, prop := range props {
+			value := effect.Attributes[prop]
+			if target.hasStyleProp(prop) {
+				target.setStyleProp(prop, value)
+			} else {
+				target.setAttr(prop, value)
+			}
+		}
+
+		if effect.Transform != "" {
+			if base, ok := target.attr("transform"); ok && effect.Additive {
+				target.setAttr("transform", base+" "+effect.Transform)
+			} else {
+				target.setAttr("transform", effect.Transform)
+			}
+		}
+
+		if effect.Motion != nil {
+			if err := applyMotion(target, effect.Motion); err != nil {
+				return "", err
+			}
+		}
+
+		if effect.HasRotate {
+			// 旋转动画的目标被包裹在使用 transform-box: fill-box 的g元素中
+			node := target
+			if parent != nil && parent.hasStyleProp("transform-box") {
+				node = parent
+				node.removeAttr("style")
+			}
+			if err := applyRotate(node, effect.Rotate); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return root.String(), nil
+}
+
+// applyRotate 绕节点的几何包围盒中心旋转
+func applyRotate(n *svgNode, angle float64) error {
+	base, hasBase := n.attr("transform")
+
+	var sb strings.Builder
+	if n.name == "g" {
+		for _, c := range n.children {
+			c.write(&sb)
+		}
+	} else {
+		// 包围盒不包含元素自身的变换
+		n.removeAttr("transform")
+		n.write(&sb)
+	}
+	minX, minY, maxX, maxY, err := converter.Bounds([]byte(sb.String()))
+	if err != nil {
+		return err
+	}
+	if minX == maxX && minY == maxY {
+		// 没有可绘制的形状，无需旋转
+		return nil
+	}
+
+	rotate := "rotate(" + formatFloat(angle) + " " + formatFloat((minX+maxX)/2) + " " + formatFloat((minY+maxY)/2) + ")"
+	if hasBase {
+		rotate = base + " " + rotate
+	}
+	n.setAttr("transform", rotate)
+	return nil
+}
+
+// applyMotion 将元素移动到路径上的对应位置
+func applyMotion(n *svgNode, m *animation.Motion) error {
+	x, y, angle, err := converter.PathPoint(m.Path, m.Progress)
+	if err != nil {
+		return err
+	}
+
+	transform := "translate(" + formatFloat(x) + "," + formatFloat(y) + ")"
+	switch m.Rotate {
+	case "auto":
+		transform += " rotate(" + formatFloat(angle) + ")"
+	case "auto-reverse":
+		transform += " rotate(" + formatFloat(angle+180) + ")"
+	default:
+		if v, err := strconv.ParseFloat(m.Rotate, 64); err == nil && v != 0 {
+			transform += " rotate(" + formatFloat(v) + ")"
+		}
+	}
+	if base, ok := n.attr("transform"); ok {
+		transform += " " + base
+	}
+	n.setAttr("transform", transform)
+	return nil
+}
+
+// formatFloat 格式化坐标值，保留4位小数以保证输出稳定
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 4, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// renderFrames 沿动画时间轴均匀采样，栅格化每一帧
+func (sb *SVGBuilder) renderFrames(opts *FrameOptions) ([]*image.RGBA, time.Duration, error) {
+	if sb.svg == "" {
+		sb = sb.Build()
+	}
+	if sb.hasError != nil {
+		return nil, 0, sb.hasError
+	}
+
+	var o FrameOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Frames <= 0 {
+		o.Frames = DefaultFrameCount
+	}
+	if o.Scale <= 0 {
+		o.Scale = 1
+	}
+
+	anims := sb.animManager().GetAnimations()
+	duration := o.Duration
+	if duration <= 0 {
+		duration = animation.TimelineDuration(anims)
+	}
+	if duration <= 0 {
+		// 没有可采样的动画，只输出一帧
+		o.Frames = 1
+		duration = 1
+	}
+
+	frames := make([]*image.RGBA, 0, o.Frames)
+	for i := 0; i < o.Frames; i++ {
+		t := duration * float64(i) / float64(o.Frames)
+		svg, err := snapshotSVG(sb.svg, anims, t, sb.idPrefix)
+		if err != nil {
+			return nil, 0, err
+		}
+		img, err := converter.RenderImage([]byte(svg), sb.width, sb.height, o.Scale)
+		if err != nil {
+			return nil, 0, err
+		}
+		frames = append(frames, img)
+	}
+
+	delay := time.Duration(duration / float64(o.Frames) * float64(time.Second))
+	return frames, delay, nil
+}
+
+// ToGIF 按动画时间轴采样并导出为动画GIF，opts 为 nil 时使用默认选项
+func (sb *SVGBuilder) ToGIF(opts *FrameOptions) ([]byte, error) {
+	frames, delay, err := sb.renderFrames(opts)
+	if err != nil {
+		return nil, err
+	}
+	loop := 0
+	if opts != nil {
+		loop = opts.LoopCount
+	}
+	return converter.EncodeGIF(frames, delay, loop)
+}
+
+// ToAPNG 按动画时间轴采样并导出为动画PNG，支持完整的透明度
+func (sb *SVGBuilder) ToAPNG(opts *FrameOptions) ([]byte, error) {
+	frames, delay, err := sb.renderFrames(opts)
+	if err != nil {
+		return nil, err
+	}
+	loop := 0
+	if opts != nil {
+		loop = opts.LoopCount
+	}
+	return converter.EncodeAPNG(frames, delay, loop)
+}
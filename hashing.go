@@ -0,0 +1,47 @@
+package pixelnebula
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"hash"
+)
+
+// WithHashFunc 设置计算头像ID哈希的函数
+// newHash 每次调用都应返回新的哈希实例，并行渲染时每个worker使用独立的实例
+// 更换哈希函数会改变所有头像，已有的缓存会被清空，nil 表示恢复默认的 sha256
+func (pn *PixelNebula) WithHashFunc(newHash func() hash.Hash) *PixelNebula {
+	pn.HashFunc = newHash
+	pn.Namespaced = false
+	pn.Hasher = pn.newHasher()
+	if pn.Cache != nil {
+		pn.Cache.Clear()
+	}
+	return pn
+}
+
+// WithNamespace 使用以namespace为密钥的HMAC-SHA256计算头像ID哈希
+// 不同namespace下同一ID生成的头像互不相关，不知道密钥时也无法由头像反推或验证ID
+func (pn *PixelNebula) WithNamespace(namespace string) *PixelNebula {
+	if namespace == "" {
+		return pn.WithHashFunc(nil)
+	}
+	pn.WithHashFunc(namespaceHash(namespace))
+	pn.Namespaced = true
+	return pn
+}
+
+// namespaceHash 返回以namespace为密钥的HMAC-SHA256构造函数
+func namespaceHash(namespace string) func() hash.Hash {
+	key := []byte(namespace)
+	return func() hash.Hash {
+		return hmac.New(sha256.New, key)
+	}
+}
+
+// newHasher 创建一个新的哈希实例
+func (pn *PixelNebula) newHasher() hash.Hash {
+	if pn.HashFunc != nil {
+		return pn.HashFunc()
+	}
+	return sha256.New()
+}
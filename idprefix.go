@@ -0,0 +1,68 @@
+package pixelnebula
+
+import (
+	"regexp"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+var (
+	// idPrefixRegex 合法的ID前缀
+	idPrefixRegex = regexp.MustCompile(`^[A-Za-z_][\w-]*$`)
+	// idAttrRegex 匹配元素的id属性
+	idAttrRegex = regexp.MustCompile(`(\sid=['"])([^'"]+)(['"])`)
+	// idRefRegex 匹配 href="#x"、url(#x) 以及CSS选择器中的 #x
+	idRefRegex = regexp.MustCompile(`#([A-Za-z_][\w.-]*)`)
+	// keyframesRegex 匹配关键帧名称
+	keyframesRegex = regexp.MustCompile(`@keyframes\s+([\w-]+)`)
+	// styleBlockRegex 匹配 <style> 元素
+	styleBlockRegex = regexp.MustCompile(`(?s)<style[^>]*>.*?</style>`)
+	// cssIdentRegex 匹配CSS标识符
+	cssIdentRegex = regexp.MustCompile(`[A-Za-z_][\w-]*`)
+)
+
+// SetIDPrefix 设置元素ID前缀，在同一页面内联多个头像时避免ID冲突
+// 所有ID及其引用（动画 href、渐变 url(#...)、<style> 中的选择器和关键帧名称）都会加上该前缀
+func (sb *SVGBuilder) SetIDPrefix(prefix string) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	if prefix != "" && !idPrefixRegex.MatchString(prefix) {
+		sb.hasError = errors.ErrInvalidIDPrefix
+		return sb
+	}
+	sb.idPrefix = prefix
+	return sb
+}
+
+// prefixIDs 为SVG中的所有ID及其引用加上前缀
+func prefixIDs(svg, prefix string) string {
+	ids := make(map[string]bool)
+	for _, m := range idAttrRegex.FindAllStringSubmatch(svg, -1) {
+		ids[m[2]] = true
+	}
+	keyframes := make(map[string]bool)
+	for _, m := range keyframesRegex.FindAllStringSubmatch(svg, -1) {
+		keyframes[m[1]] = true
+	}
+
+	svg = idAttrRegex.ReplaceAllString(svg, "${1}"+prefix+"${2}${3}")
+	svg = idRefRegex.ReplaceAllStringFunc(svg, func(ref string) string {
+		if ids[ref[1:]] {
+			return "#" + prefix + ref[1:]
+		}
+		return ref
+	})
+
+	if len(keyframes) == 0 {
+		return svg
+	}
+	return styleBlockRegex.ReplaceAllStringFunc(svg, func(block string) string {
+		return cssIdentRegex.ReplaceAllStringFunc(block, func(ident string) string {
+			if keyframes[ident] {
+				return prefix + ident
+			}
+			return ident
+		})
+	})
+}
@@ -0,0 +1,65 @@
+package pixelnebula
+
+import (
+	"strings"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+// MaskShape 头像的裁剪形状
+type MaskShape string
+
+// 预定义裁剪形状常量
+const (
+	MaskNone          MaskShape = ""               // 不裁剪
+	MaskCircle        MaskShape = "circle"         // 圆形
+	MaskRoundedSquare MaskShape = "rounded-square" // 圆角正方形
+	MaskSquircle      MaskShape = "squircle"       // 超椭圆
+	MaskHexagon       MaskShape = "hexagon"        // 六边形
+)
+
+// maskID 裁剪路径的元素ID
+const maskID = "avatar-mask"
+
+// maskShapes 各裁剪形状在231网格上的轮廓
+var maskShapes = map[MaskShape]string{
+	MaskCircle:        `<circle cx="115.5" cy="115.5" r="115.5"/>`,
+	MaskRoundedSquare: `<rect width="231" height="231" rx="46.2" ry="46.2"/>`,
+	MaskSquircle:      `<path d="M115.5 0C207.9 0 231 23.1 231 115.5S207.9 231 115.5 231S0 207.9 0 115.5S23.1 0 115.5 0Z"/>`,
+	MaskHexagon:       `<path d="M115.5 0L215.53 57.75V173.25L115.5 231L15.47 173.25V57.75Z"/>`,
+}
+
+// SetMask 设置裁剪形状，整个头像（包括背景）都会被裁剪为该形状
+func (sb *SVGBuilder) SetMask(shape MaskShape) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	if _, ok := maskShapes[shape]; !ok && shape != MaskNone {
+		sb.hasError = errors.ErrInvalidMaskShape
+		return sb
+	}
+	sb.mask = shape
+	return sb
+}
+
+// applyMask 将画布内容包裹在引用裁剪路径的g元素中
+func applyMask(svg string, shape MaskShape) (string, error) {
+	clip, ok := maskShapes[shape]
+	if !ok {
+		return "", errors.ErrInvalidMaskShape
+	}
+	if !strings.HasPrefix(svg, artworkSVGStart) || !strings.HasSuffix(svg, "</svg>") {
+		return "", errors.ErrInvalidSVG
+	}
+
+	body := svg[len(artworkSVGStart) : len(svg)-len("</svg>")]
+	var sb strings.Builder
+	sb.Grow(len(svg) + len(clip) + 128)
+	sb.WriteString(artworkSVGStart)
+	sb.WriteString(`<defs><clipPath id="` + maskID + `">`)
+	sb.WriteString(clip)
+	sb.WriteString(`</clipPath></defs><g clip-path="url(#` + maskID + `)">`)
+	sb.WriteString(body)
+	sb.WriteString("</g></svg>")
+	return sb.String(), nil
+}
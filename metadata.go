@@ -0,0 +1,268 @@
+package pixelnebula
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/landaiqing/go-pixelnebula/animation"
+	"github.com/landaiqing/go-pixelnebula/errors"
+	"github.com/landaiqing/go-pixelnebula/style"
+)
+
+// MetadataFormat 嵌入SVG的元数据格式
+type MetadataFormat string
+
+// 预定义元数据格式常量
+const (
+	MetadataNone MetadataFormat = ""     // 不嵌入元数据（默认）
+	MetadataJSON MetadataFormat = "json" // JSON
+	MetadataRDF  MetadataFormat = "rdf"  // RDF/XML
+)
+
+const (
+	// modulePath 本库的模块路径，用于标识生成器和查找版本
+	modulePath = "github.com/landaiqing/go-pixelnebula"
+	// rdfNS RDF 命名空间
+	rdfNS = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	// metadataNS 元数据属性的命名空间
+	metadataNS = "https://" + modulePath + "#"
+)
+
+var (
+	// metadataRegex 匹配本库嵌入的元数据元素
+	metadataRegex = regexp.MustCompile(`(?s)<metadata data-generator="` + regexp.QuoteMeta(modulePath) + `">(.*?)</metadata>`)
+
+	// libraryVersion 本库的模块版本，从构建信息中读取
+	libraryVersion = sync.OnceValue(func() string {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return "(unknown)"
+		}
+		if info.Main.Path == modulePath {
+			return info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				if dep.Replace != nil {
+					return dep.Replace.Version
+				}
+				return dep.Version
+			}
+		}
+		return "(unknown)"
+	})
+)
+
+// Metadata 嵌入SVG的生成信息，用于追查头像是由哪个版本、风格、主题和设置生成的
+type Metadata struct {
+	Generator   string              `json:"generator"`            // 生成器，固定为本库的模块路径
+	Version     string              `json:"version"`              // 本库的版本
+	Selection   SelectionVersion    `json:"selection"`            // 选择算法版本
+	Parts       []PartMetadata      `json:"parts"`                // 各部分的风格和主题
+	Width       int                 `json:"width"`                // 输出宽度（像素）
+	Height      int                 `json:"height"`               // 输出高度（像素）
+	Animations  []AnimationMetadata `json:"animations,omitempty"` // 动画
+	ContentHash string              `json:"contentHash"`          // 去掉元数据后SVG的 sha256 摘要
+	Format      MetadataFormat      `json:"-"`                    // 读取时的元数据格式
+	Verified    bool                `json:"-"`                    // 读取时内容摘要是否与SVG一致
+}
+
+// PartMetadata 一个部分的风格和主题
+type PartMetadata struct {
+	Part       style.ShapeType `json:"part"`
+	Style      style.StyleType `json:"style,omitempty"`
+	StyleIndex int             `json:"styleIndex"`
+	Theme      int             `json:"theme"`
+}
+
+// AnimationMetadata 动画的类型和目标元素
+type AnimationMetadata struct {
+	Type   animation.AnimationType `json:"type"`
+	Target string                  `json:"target"`
+}
+
+// validMetadata 判断元数据格式是否有效
+func validMetadata(format MetadataFormat) bool {
+	return format == MetadataNone || format == MetadataJSON || format == MetadataRDF
+}
+
+// WithMetadata 设置在SVG中嵌入元数据的格式，对 Generate 和 GenerateBatch 都生效
+// 格式无效时记录错误且不修改设置，见 Err
+func (pn *PixelNebula) WithMetadata(format MetadataFormat) *PixelNebula {
+	if !validMetadata(format) {
+		return pn.setErr(fmt.Errorf("%w: unknown metadata format %q", errors.ErrInvalidOption, format))
+	}
+	pn.Metadata = format
+	return pn
+}
+
+// SetMetadata 设置在SVG中嵌入元数据的格式，MetadataNone 表示不嵌入
+func (sb *SVGBuilder) SetMetadata(format MetadataFormat) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	if !validMetadata(format) {
+		sb.hasError = fmt.Errorf("%w: unknown metadata format %q", errors.ErrInvalidOption, format)
+		return sb
+	}
+	sb.metadata = format
+	return sb
+}
+
+// addMetadata 在根元素（及无障碍的 title、desc）之后插入元数据
+func (sb *SVGBuilder) addMetadata(svg string) (string, error) {
+	end := strings.Index(svg, ">")
+	if !strings.HasPrefix(svg, "<svg") || end == -1 {
+		return "", errors.ErrInvalidSVG
+	}
+	keys, err := sb.pn.partKeys(sb.id, sb.options())
+	if err != nil {
+		return "", err
+	}
+
+	selection := sb.pn.Selection
+	if selection == 0 {
+		selection = SelectionV1
+	}
+	m := &Metadata{
+		Generator:   modulePath,
+		Version:     libraryVersion(),
+		Selection:   selection,
+		Width:       sb.width,
+		Height:      sb.height,
+		ContentHash: contentHash(svg),
+	}
+	for _, part := range partOrder {
+		name, _ := sb.pn.StyleManager.GetStyleName(keys[part][0])
+		m.Parts = append(m.Parts, PartMetadata{Part: part, Style: name, StyleIndex: keys[part][0], Theme: keys[part][1]})
+	}
+	for _, anim := range sb.animManager().GetAnimations() {
+		m.Animations = append(m.Animations, AnimationMetadata{Type: anim.GetType(), Target: anim.GetTargetID()})
+	}
+
+	var content string
+	if sb.metadata == MetadataRDF {
+		content = m.rdf()
+	} else {
+		data, err := json.Marshal(m)
+		if err != nil {
+			return "", err
+		}
+		// json.Marshal 会转义 <、> 和 &，可以直接作为XML文本
+		content = string(data)
+	}
+
+	pos := end + 1
+	if loc := accessibleTextRegex.FindStringIndex(svg[pos:]); loc != nil {
+		pos += loc[1]
+	}
+	return svg[:pos] + `<metadata data-generator="` + modulePath + `">` + content + "</metadata>" + svg[pos:], nil
+}
+
+// rdf 将元数据格式化为 RDF/XML
+func (m *Metadata) rdf() string {
+	var b strings.Builder
+	attr := func(name, value string) {
+		b.WriteString(" pn:" + name + `="`)
+		xml.EscapeText(&b, []byte(value))
+		b.WriteString(`"`)
+	}
+
+	b.WriteString(`<rdf:RDF xmlns:rdf="` + rdfNS + `" xmlns:pn="` + metadataNS + `"><rdf:Description rdf:about=""`)
+	attr("generator", m.Generator)
+	attr("version", m.Version)
+	attr("selection", strconv.Itoa(int(m.Selection)))
+	attr("width", strconv.Itoa(m.Width))
+	attr("height", strconv.Itoa(m.Height))
+	attr("contentHash", m.ContentHash)
+	b.WriteString("><pn:parts><rdf:Seq>")
+	for _, p := range m.Parts {
+		b.WriteString("<rdf:li")
+		attr("part", string(p.Part))
+		attr("style", string(p.Style))
+		attr("styleIndex", strconv.Itoa(p.StyleIndex))
+		attr("theme", strconv.Itoa(p.Theme))
+		b.WriteString("/>")
+	}
+	b.WriteString("</rdf:Seq></pn:parts><pn:animations><rdf:Seq>")
+	for _, a := range m.Animations {
+		b.WriteString("<rdf:li")
+		attr("type", string(a.Type))
+		attr("target", a.Target)
+		b.WriteString("/>")
+	}
+	b.WriteString("</rdf:Seq></pn:animations></rdf:Description></rdf:RDF>")
+	return b.String()
+}
+
+// rdfMetadata 解析 RDF/XML 格式的元数据，按本地名称匹配元素和属性
+type rdfMetadata struct {
+	Description struct {
+		Generator   string `xml:"generator,attr"`
+		Version     string `xml:"version,attr"`
+		Selection   int    `xml:"selection,attr"`
+		Width       int    `xml:"width,attr"`
+		Height      int    `xml:"height,attr"`
+		ContentHash string `xml:"contentHash,attr"`
+		Parts       []struct {
+			Part       string `xml:"part,attr"`
+			Style      string `xml:"style,attr"`
+			StyleIndex int    `xml:"styleIndex,attr"`
+			Theme      int    `xml:"theme,attr"`
+		} `xml:"parts>Seq>li"`
+		Animations []struct {
+			Type   string `xml:"type,attr"`
+			Target string `xml:"target,attr"`
+		} `xml:"animations>Seq>li"`
+	} `xml:"Description"`
+}
+
+// ReadMetadata 读取SVG中嵌入的元数据，并校验内容摘要
+// 摘要不一致（例如SVG在生成后被修改过）时仍返回元数据，Verified 为 false
+func ReadMetadata(svg string) (*Metadata, error) {
+	loc := metadataRegex.FindStringSubmatchIndex(svg)
+	if loc == nil {
+		return nil, fmt.Errorf("%w: no metadata", errors.ErrInvalidSVG)
+	}
+	content := strings.TrimSpace(svg[loc[2]:loc[3]])
+
+	m := &Metadata{}
+	if strings.HasPrefix(content, "{") {
+		if err := json.Unmarshal([]byte(content), m); err != nil {
+			return nil, fmt.Errorf("%w: invalid metadata: %v", errors.ErrInvalidSVG, err)
+		}
+		m.Format = MetadataJSON
+	} else {
+		var r rdfMetadata
+		if err := xml.Unmarshal([]byte(content), &r); err != nil {
+			return nil, fmt.Errorf("%w: invalid metadata: %v", errors.ErrInvalidSVG, err)
+		}
+		d := r.Description
+		m.Generator, m.Version, m.Selection = d.Generator, d.Version, SelectionVersion(d.Selection)
+		m.Width, m.Height, m.ContentHash = d.Width, d.Height, d.ContentHash
+		for _, p := range d.Parts {
+			m.Parts = append(m.Parts, PartMetadata{Part: style.ShapeType(p.Part), Style: style.StyleType(p.Style), StyleIndex: p.StyleIndex, Theme: p.Theme})
+		}
+		for _, a := range d.Animations {
+			m.Animations = append(m.Animations, AnimationMetadata{Type: animation.AnimationType(a.Type), Target: a.Target})
+		}
+		m.Format = MetadataRDF
+	}
+
+	m.Verified = m.ContentHash == contentHash(svg[:loc[0]]+svg[loc[1]:])
+	return m, nil
+}
+
+// contentHash 计算SVG内容的摘要
+func contentHash(svg string) string {
+	sum := sha256.Sum256([]byte(svg))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
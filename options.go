@@ -0,0 +1,376 @@
+package pixelnebula
+
+import (
+	"fmt"
+	"hash"
+	"log/slog"
+
+	"github.com/landaiqing/go-pixelnebula/animation"
+	"github.com/landaiqing/go-pixelnebula/cache"
+	"github.com/landaiqing/go-pixelnebula/errors"
+	"github.com/landaiqing/go-pixelnebula/style"
+	"github.com/landaiqing/go-pixelnebula/theme"
+)
+
+// Option 配置 New 创建的实例，参数错误时返回错误而不是 panic
+type Option func(c *config) error
+
+// config New 收集的配置，全部选项应用后统一校验
+type config struct {
+	style         style.StyleType
+	hasStyle      bool
+	theme         int
+	hasTheme      bool
+	width         int
+	height        int
+	padding       int
+	aspect        string
+	cache         *cache.CacheOptions
+	animations    []animation.Animation
+	animationMode animation.Mode
+	reducedMotion bool
+	parallel      bool
+	pool          int
+	themes        []theme.Theme
+	styles        []style.StyleSet
+	logger        *slog.Logger
+	hashFunc      func() hash.Hash
+	namespaced    bool
+	selection     SelectionVersion
+	partStyles    map[style.ShapeType][]style.StyleType
+	metadata      MetadataFormat
+}
+
+// New 使用选项创建一个PixelNebula实例
+// 所有选项都会被校验，返回的错误包含全部问题，可以用 errors.Is 匹配对应的错误常量
+func New(opts ...Option) (*PixelNebula, error) {
+	c := &config{width: ArtworkSize, height: ArtworkSize}
+
+	var errs []error
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	pn := NewPixelNebula()
+	pn.Logger = c.logger
+	if c.hashFunc != nil {
+		pn.WithHashFunc(c.hashFunc)
+		pn.Namespaced = c.namespaced
+	}
+	pn.Selection = c.selection
+	pn.Metadata = c.metadata
+	if c.themes != nil {
+		pn.ThemeManager.CustomizeTheme(c.themes)
+	}
+	if c.styles != nil {
+		pn.StyleManager.CustomizeStyle(c.styles)
+	}
+
+	if c.hasStyle {
+		index, err := pn.StyleManager.GetStyleIndex(c.style)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %q", err, c.style))
+		} else {
+			pn.Options.StyleIndex = index
+		}
+	}
+	if c.hasTheme {
+		if index := pn.Options.StyleIndex; index >= 0 {
+			if count := pn.ThemeManager.ThemeCount(index); c.theme < 0 || c.theme >= count {
+				errs = append(errs, &errors.IndexError{Kind: errors.IndexTheme, Got: c.theme, Max: count - 1, Style: index})
+			}
+		} else if c.theme < 0 {
+			errs = append(errs, fmt.Errorf("%w: got %d", errors.ErrInvalidTheme, c.theme))
+		}
+		pn.Options.ThemeIndex = c.theme
+	}
+
+	for part, styles := range c.partStyles {
+		indexes, err := pn.resolvePartStyles(part, styles)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(indexes) > 0 {
+			if pn.PartStyles == nil {
+				pn.PartStyles = make(map[style.ShapeType][]int)
+			}
+			pn.PartStyles[part] = indexes
+		}
+	}
+
+	pn.Width, pn.Height, pn.Padding, pn.AspectRatio = c.width, c.height, c.padding, c.aspect
+	if _, err := pn.layout().rootTag(); err != nil {
+		errs = append(errs, fmt.Errorf("%w: width %d, height %d, padding %d, preserveAspectRatio %q",
+			err, c.width, c.height, c.padding, c.aspect))
+	}
+
+	if c.cache != nil {
+		errs = append(errs, validateCacheOptions(*c.cache)...)
+	}
+
+	for i, anim := range c.animations {
+		if anim == nil {
+			errs = append(errs, fmt.Errorf("%w: animation %d is nil", errors.ErrInvalidAnimation, i))
+			continue
+		}
+		if v, ok := anim.(animation.Validator); ok {
+			if err := v.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	for _, anim := range c.animations {
+		pn.AnimManager.AddAnimation(anim)
+	}
+	if c.animationMode != "" {
+		pn.AnimManager.SetMode(c.animationMode)
+	}
+	pn.AnimManager.SetReducedMotion(c.reducedMotion)
+	pn.Options.ParallelRender = c.parallel
+	if c.pool > 0 {
+		pn.Options.ConcurrencyPool = c.pool
+	}
+	if c.cache != nil {
+		pn.WithCache(*c.cache)
+	}
+	return pn, nil
+}
+
+// validateCacheOptions 校验缓存选项
+func validateCacheOptions(o cache.CacheOptions) []error {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]interface{}{errors.ErrInvalidCacheOptions}, args...)...))
+	}
+
+	if o.Size < 0 {
+		invalid("negative size %d", o.Size)
+	}
+	if o.Expiration < 0 {
+		invalid("negative expiration %s", o.Expiration)
+	}
+	switch o.EvictionType {
+	case "", "lru", "fifo":
+	default:
+		invalid("unknown eviction type %q", o.EvictionType)
+	}
+	if c := o.Compression; c.Enabled {
+		if c.Level < -2 || c.Level > 9 {
+			invalid("compression level %d out of range [-2, 9]", c.Level)
+		}
+		if c.MinSize < 0 {
+			invalid("negative compression min size %d", c.MinSize)
+		}
+		if c.CompressionRatio <= 0 || c.CompressionRatio > 1 {
+			invalid("compression ratio %g out of range (0, 1]", c.CompressionRatio)
+		}
+	}
+	if m := o.Monitoring; m.Enabled {
+		if m.SampleInterval <= 0 || m.AdjustInterval <= 0 {
+			invalid("monitor intervals must be positive")
+		}
+		if m.MinSize < 0 || m.MaxSize < m.MinSize {
+			invalid("monitor size range [%d, %d] is invalid", m.MinSize, m.MaxSize)
+		}
+		if m.TargetHitRate < 0 || m.TargetHitRate > 1 {
+			invalid("target hit rate %g out of range [0, 1]", m.TargetHitRate)
+		}
+	}
+	return errs
+}
+
+// WithStyle 设置固定风格
+func WithStyle(s style.StyleType) Option {
+	return func(c *config) error {
+		c.style, c.hasStyle = s, true
+		return nil
+	}
+}
+
+// WithTheme 设置固定主题
+func WithTheme(index int) Option {
+	return func(c *config) error {
+		c.theme, c.hasTheme = index, true
+		return nil
+	}
+}
+
+// WithSize 设置尺寸
+func WithSize(width, height int) Option {
+	return func(c *config) error {
+		c.width, c.height = width, height
+		return nil
+	}
+}
+
+// WithPadding 设置头像四周的留白（像素）
+func WithPadding(padding int) Option {
+	return func(c *config) error {
+		c.padding = padding
+		return nil
+	}
+}
+
+// WithPreserveAspectRatio 设置宽高比不一致时的对齐方式
+func WithPreserveAspectRatio(value string) Option {
+	return func(c *config) error {
+		c.aspect = value
+		return nil
+	}
+}
+
+// WithCustomizeTheme 设置自定义主题
+func WithCustomizeTheme(themes []theme.Theme) Option {
+	return func(c *config) error {
+		if len(themes) == 0 {
+			return fmt.Errorf("%w: custom themes are empty", errors.ErrInvalidOption)
+		}
+		c.themes = themes
+		return nil
+	}
+}
+
+// WithCustomizeStyle 设置自定义风格
+func WithCustomizeStyle(styles []style.StyleSet) Option {
+	return func(c *config) error {
+		if len(styles) == 0 {
+			return fmt.Errorf("%w: custom styles are empty", errors.ErrInvalidOption)
+		}
+		c.styles = styles
+		return nil
+	}
+}
+
+// WithCache 设置缓存选项
+func WithCache(options cache.CacheOptions) Option {
+	return func(c *config) error {
+		c.cache = &options
+		return nil
+	}
+}
+
+// WithDefaultCache 使用默认缓存选项
+func WithDefaultCache() Option {
+	return WithCache(cache.DefaultCacheOptions)
+}
+
+// WithAnimations 添加动画效果
+func WithAnimations(anims ...animation.Animation) Option {
+	return func(c *config) error {
+		c.animations = append(c.animations, anims...)
+		return nil
+	}
+}
+
+// WithAnimationMode 设置动画输出模式
+func WithAnimationMode(mode animation.Mode) Option {
+	return func(c *config) error {
+		switch mode {
+		case animation.ModeSMIL, animation.ModeCSS:
+			c.animationMode = mode
+			return nil
+		}
+		return fmt.Errorf("%w: unknown animation mode %q", errors.ErrInvalidOption, mode)
+	}
+}
+
+// WithReducedMotion 设置是否响应 prefers-reduced-motion 媒体查询
+func WithReducedMotion(enabled bool) Option {
+	return func(c *config) error {
+		c.reducedMotion = enabled
+		return nil
+	}
+}
+
+// WithParallelRender 设置是否启用并行渲染
+func WithParallelRender(enabled bool) Option {
+	return func(c *config) error {
+		c.parallel = enabled
+		return nil
+	}
+}
+
+// WithConcurrencyPool 设置并发池大小
+func WithConcurrencyPool(size int) Option {
+	return func(c *config) error {
+		if size <= 0 {
+			return fmt.Errorf("%w: concurrency pool size must be positive, got %d", errors.ErrInvalidOption, size)
+		}
+		c.pool = size
+		return nil
+	}
+}
+
+// WithLogger 设置日志记录器，同时作用于缓存和缓存监控器
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) error {
+		c.logger = logger
+		return nil
+	}
+}
+
+// WithHashFunc 设置计算头像ID哈希的函数，每次调用都应返回新的哈希实例
+func WithHashFunc(newHash func() hash.Hash) Option {
+	return func(c *config) error {
+		if newHash == nil {
+			return fmt.Errorf("%w: hash func is nil", errors.ErrInvalidOption)
+		}
+		c.hashFunc, c.namespaced = newHash, false
+		return nil
+	}
+}
+
+// WithNamespace 使用以namespace为密钥的HMAC-SHA256计算头像ID哈希
+func WithNamespace(namespace string) Option {
+	return func(c *config) error {
+		if namespace == "" {
+			return fmt.Errorf("%w: namespace is empty", errors.ErrInvalidOption)
+		}
+		c.hashFunc, c.namespaced = namespaceHash(namespace), true
+		return nil
+	}
+}
+
+// WithSelectionVersion 设置选择算法版本
+func WithSelectionVersion(version SelectionVersion) Option {
+	return func(c *config) error {
+		if !validSelection(version) {
+			return fmt.Errorf("%w: unknown selection version %d", errors.ErrInvalidOption, version)
+		}
+		c.selection = version
+		return nil
+	}
+}
+
+// WithPartStyles 限制某个部分只从指定的风格中选择
+func WithPartStyles(part style.ShapeType, styles ...style.StyleType) Option {
+	return func(c *config) error {
+		if c.partStyles == nil {
+			c.partStyles = make(map[style.ShapeType][]style.StyleType)
+		}
+		c.partStyles[part] = styles
+		return nil
+	}
+}
+
+// WithMetadata 设置在SVG中嵌入元数据的格式
+func WithMetadata(format MetadataFormat) Option {
+	return func(c *config) error {
+		if !validMetadata(format) {
+			return fmt.Errorf("%w: unknown metadata format %q", errors.ErrInvalidOption, format)
+		}
+		c.metadata = format
+		return nil
+	}
+}
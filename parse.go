@@ -0,0 +1,263 @@
+package pixelnebula
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/landaiqing/go-pixelnebula/animation"
+	"github.com/landaiqing/go-pixelnebula/errors"
+	"github.com/landaiqing/go-pixelnebula/style"
+	"github.com/landaiqing/go-pixelnebula/theme"
+)
+
+var (
+	// rootAttrRegex 匹配根元素的属性
+	rootAttrRegex = regexp.MustCompile(`\s([\w:-]+)="([^"]*)"`)
+	// accessibleAttrRegex 匹配 addAccessibility 添加到根元素的属性
+	accessibleAttrRegex = regexp.MustCompile(`\srole="img" aria-label="[^"]*"`)
+	// accessibleTextRegex 匹配 addAccessibility 插入的 title 和 desc 元素
+	accessibleTextRegex = regexp.MustCompile(`^(?s)<title>(.*?)</title><desc>(.*?)</desc>`)
+	// headIDRegex 匹配头部形状的ID，用于识别ID前缀
+	headIDRegex = regexp.MustCompile(`\sid='([^']*)head'`)
+)
+
+// ParseSVG 解析由本库生成的SVG，还原出头像描述
+// 通过与风格中的形状比较确定各部分的风格，通过与主题比较确定主题，同时还原尺寸、背景、裁剪形状、无障碍信息和动画
+// 颜色与所有主题都不一致时 Theme 为 -1，Colors 记录实际颜色；无法区分的设置（如纯色背景）按等效的方式还原
+// 只支持SMIL模式的动画；SVG中嵌入了元数据时从中读取选择算法版本，否则 Selection 为0表示未知
+func (pn *PixelNebula) ParseSVG(svg string) (*AvatarSpec, error) {
+	end := strings.Index(svg, ">")
+	if !strings.HasPrefix(svg, "<svg") || end < 0 || !strings.HasSuffix(svg, pn.SvgEnd) {
+		return nil, errors.ErrInvalidSVG
+	}
+	root := accessibleAttrRegex.ReplaceAllString(svg[:end+1], "")
+	body := svg[end+1:]
+
+	spec := &AvatarSpec{Version: SpecVersion, AnimationMode: animation.ModeSMIL}
+	if m := accessibleTextRegex.FindStringSubmatch(body); m != nil {
+		spec.Accessible = true
+		spec.Title, spec.Description = html.UnescapeString(m[1]), html.UnescapeString(m[2])
+		body = body[len(m[0]):]
+	}
+	if metadataRegex.MatchString(body) {
+		m, err := ReadMetadata(svg)
+		if err != nil {
+			return nil, err
+		}
+		spec.Metadata, spec.Selection = m.Format, m.Selection
+		body = metadataRegex.ReplaceAllString(body, "")
+	}
+	if err := parseLayout(root, spec); err != nil {
+		return nil, err
+	}
+
+	if m := headIDRegex.FindStringSubmatch(body); m != nil && m[1] != "" {
+		spec.IDPrefix = m[1]
+		body = unprefixIDs(body, m[1])
+	}
+
+	if start := strings.Index(body, `<clipPath id="`+maskID+`">`); start >= 0 {
+		clip := body[start+len(`<clipPath id="`+maskID+`">`):]
+		for shape, outline := range maskShapes {
+			if strings.HasPrefix(clip, outline+"</clipPath>") {
+				spec.Mask = shape
+			}
+		}
+		if spec.Mask == MaskNone {
+			return nil, fmt.Errorf("%w: unknown mask shape", errors.ErrInvalidSVG)
+		}
+	}
+
+	switch {
+	case strings.Contains(body, `<linearGradient id="`+backgroundID+`"`):
+		spec.Background = BackgroundLinearGradient
+	case strings.Contains(body, `<radialGradient id="`+backgroundID+`"`):
+		spec.Background = BackgroundRadialGradient
+	case strings.Contains(body, `<pattern id="`+backgroundID+`"`):
+		spec.Background = BackgroundPattern
+	}
+	if spec.Background != BackgroundTheme {
+		// 背景形状的基础色保留在 url() 之后，还原为普通填充后即可匹配形状
+		body = strings.Replace(body, "fill:url(#"+backgroundID+") ", "fill:", 1)
+	}
+
+	for _, part := range partOrder {
+		p, ok := pn.matchPart(body, part)
+		if !ok {
+			if part == style.TypeEnv {
+				spec.SansEnv = true
+				continue
+			}
+			return nil, fmt.Errorf("%w: no style matches part %q", errors.ErrInvalidSVG, part)
+		}
+		spec.Parts = append(spec.Parts, p)
+	}
+
+	anims, err := animation.ParseSVG(body)
+	if err != nil {
+		return nil, err
+	}
+	for _, anim := range anims {
+		a, err := animation.ToSpec(anim)
+		if err != nil {
+			return nil, err
+		}
+		spec.Animations = append(spec.Animations, a)
+	}
+	return spec, nil
+}
+
+// parseLayout 由根元素还原尺寸、留白和对齐方式
+// 留白无法直接读出，逐个尝试直到生成相同的根元素
+func parseLayout(root string, spec *AvatarSpec) error {
+	if root == artworkSVGStart {
+		spec.Width, spec.Height = ArtworkSize, ArtworkSize
+		return nil
+	}
+	attrs := make(map[string]string)
+	for _, m := range rootAttrRegex.FindAllStringSubmatch(root, -1) {
+		attrs[m[1]] = m[2]
+	}
+	width, err1 := strconv.Atoi(attrs["width"])
+	height, err2 := strconv.Atoi(attrs["height"])
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("%w: unsupported root element %s", errors.ErrInvalidSVG, root)
+	}
+
+	l := layout{width: width, height: height, aspect: attrs["preserveAspectRatio"]}
+	for ; 2*l.padding < width && 2*l.padding < height; l.padding++ {
+		if tag, err := l.rootTag(); err == nil && tag == root {
+			spec.Width, spec.Height, spec.Padding, spec.AspectRatio = l.width, l.height, l.padding, l.aspect
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unsupported root element %s", errors.ErrInvalidSVG, root)
+}
+
+// unprefixIDs 去掉 prefixIDs 添加的ID前缀
+func unprefixIDs(svg, prefix string) string {
+	ids := make(map[string]bool)
+	for _, m := range idAttrRegex.FindAllStringSubmatch(svg, -1) {
+		if strings.HasPrefix(m[2], prefix) {
+			ids[m[2]] = true
+		}
+	}
+	svg = idAttrRegex.ReplaceAllStringFunc(svg, func(attr string) string {
+		m := idAttrRegex.FindStringSubmatch(attr)
+		return m[1] + strings.TrimPrefix(m[2], prefix) + m[3]
+	})
+	return idRefRegex.ReplaceAllStringFunc(svg, func(ref string) string {
+		if ids[ref[1:]] {
+			return "#" + strings.TrimPrefix(ref[1:], prefix)
+		}
+		return ref
+	})
+}
+
+// matchPart 在SVG中查找某个部分使用的风格和颜色
+// 多个风格的形状都能匹配时，优先选择形状最完整的，其次是颜色与主题一致的
+func (pn *PixelNebula) matchPart(svg string, part style.ShapeType) (PartSpec, bool) {
+	var (
+		best    PartSpec
+		found   bool
+		bestLen int
+	)
+	for i := 0; i < pn.StyleManager.StyleSetCount(); i++ {
+		shape, err := pn.StyleManager.GetShape(i, part)
+		if err != nil {
+			continue
+		}
+		colors, ok := matchShape(svg, shape)
+		if !ok {
+			continue
+		}
+		p := pn.matchTheme(i, part, shape, colors)
+		if found && (len(shape) < bestLen || len(shape) == bestLen && (p.Theme < 0 || best.Theme >= 0)) {
+			continue
+		}
+		best, found, bestLen = p, true, len(shape)
+	}
+	return best, found
+}
+
+// matchTheme 查找与SVG中颜色一致的主题，没有一致的主题时记录实际颜色
+func (pn *PixelNebula) matchTheme(styleIndex int, part style.ShapeType, shape string, colors []string) PartSpec {
+	name, _ := pn.StyleManager.GetStyleName(styleIndex)
+	p := PartSpec{Part: part, Style: name, StyleIndex: styleIndex, Theme: -1}
+	defaults := colorRegex.FindAllStringSubmatch(shape, -1)
+
+	for j := 0; j < pn.ThemeManager.ThemeCount(styleIndex); j++ {
+		themePart, err := pn.ThemeManager.GetTheme(styleIndex, j)
+		if err != nil {
+			continue
+		}
+		scheme, ok := themePart[string(part)]
+		if !ok {
+			continue
+		}
+		match := true
+		for k, c := range colors {
+			want := defaults[k][1]
+			if k < len(scheme) {
+				want = strings.TrimPrefix(scheme[k], "#")
+			}
+			match = match && c == want
+		}
+		if match {
+			p.Theme, p.Colors = j, scheme
+			return p
+		}
+	}
+
+	p.Colors = make(theme.ColorScheme, len(colors))
+	for k, c := range colors {
+		p.Colors[k] = "#" + c
+	}
+	return p
+}
+
+// matchShape 在SVG中查找由形状模板渲染的片段，返回各颜色位置的实际颜色
+// 模板按颜色位置切分为字面片段逐段比较，颜色位置为 # 到下一个分号之间的内容，不需要编译正则
+func matchShape(svg, shape string) ([]string, bool) {
+	locs := colorRegex.FindAllStringIndex(shape, -1)
+	literals := make([]string, 0, len(locs)+1)
+	last := 0
+	for _, loc := range locs {
+		literals = append(literals, shape[last:loc[0]])
+		last = loc[1]
+	}
+	literals = append(literals, shape[last:])
+
+	for start := 0; start <= len(svg); start++ {
+		i := strings.Index(svg[start:], literals[0])
+		if i < 0 {
+			return nil, false
+		}
+		start += i
+		if colors, ok := matchColors(svg[start+len(literals[0]):], literals[1:]); ok {
+			return colors, true
+		}
+	}
+	return nil, false
+}
+
+// matchColors 依次匹配颜色位置和其后的字面片段
+func matchColors(s string, literals []string) ([]string, bool) {
+	colors := make([]string, 0, len(literals))
+	for _, literal := range literals {
+		end := strings.IndexByte(s, ';')
+		if !strings.HasPrefix(s, "#") || end < 0 {
+			return nil, false
+		}
+		colors = append(colors, s[1:end])
+		s = s[end+1:]
+		if !strings.HasPrefix(s, literal) {
+			return nil, false
+		}
+		s = s[len(literal):]
+	}
+	return colors, true
+}
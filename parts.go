@@ -0,0 +1,104 @@
+package pixelnebula
+
+import (
+	"fmt"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+	"github.com/landaiqing/go-pixelnebula/style"
+	"github.com/landaiqing/go-pixelnebula/theme"
+)
+
+// SetPart 将某个部分固定为指定风格和主题，其余部分仍由ID决定
+// themeIdx 为 -1 时沿用按ID选择的主题（超出该风格的主题数量时取模），其他超出范围的值记录 IndexError
+func (sb *SVGBuilder) SetPart(part style.ShapeType, styleIdx, themeIdx int) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	if !isPart(part) {
+		sb.hasError = fmt.Errorf("%w: %q", errors.ErrInvalidShapeType, part)
+		return sb
+	}
+	if styleCount := sb.pn.ThemeManager.StyleCount(); styleIdx < 0 || styleIdx >= styleCount {
+		sb.hasError = &errors.IndexError{Kind: errors.IndexStyle, Got: styleIdx, Max: styleCount - 1, Style: -1}
+		return sb
+	}
+	if themeCount := sb.pn.ThemeManager.ThemeCount(styleIdx); themeIdx < -1 || themeIdx >= themeCount {
+		sb.hasError = &errors.IndexError{Kind: errors.IndexTheme, Got: themeIdx, Max: themeCount - 1, Style: styleIdx}
+		return sb
+	}
+	if _, err := sb.pn.StyleManager.GetShape(styleIdx, part); err != nil {
+		sb.hasError = err
+		return sb
+	}
+	if sb.parts == nil {
+		sb.parts = make(map[style.ShapeType][2]int)
+	}
+	sb.parts[part] = [2]int{styleIdx, themeIdx}
+	return sb
+}
+
+// SetPartColors 替换某个部分的颜色，按形状中颜色出现的顺序对应
+// 颜色为空字符串或数量不足时使用主题中的颜色
+func (sb *SVGBuilder) SetPartColors(part style.ShapeType, colors theme.ColorScheme) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	if !isPart(part) {
+		sb.hasError = fmt.Errorf("%w: %q", errors.ErrInvalidShapeType, part)
+		return sb
+	}
+	for _, c := range colors {
+		if c != "" && !hexColorRegex.MatchString(c) {
+			sb.hasError = fmt.Errorf("%w: %q", errors.ErrInvalidColor, c)
+			return sb
+		}
+	}
+	if sb.partColors == nil {
+		sb.partColors = make(map[style.ShapeType]theme.ColorScheme)
+	}
+	sb.partColors[part] = append(theme.ColorScheme(nil), colors...)
+	return sb
+}
+
+// isPart 判断是否为头像的组成部分
+func isPart(part style.ShapeType) bool {
+	for _, p := range partOrder {
+		if p == part {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPartOverrides 用固定的部分替换按ID选择的风格和主题
+func (pn *PixelNebula) applyPartOverrides(keys map[style.ShapeType][2]int, parts map[style.ShapeType][2]int) {
+	for part, key := range parts {
+		if key[1] < 0 {
+			key[1] = keys[part][1]
+			if count := pn.ThemeManager.ThemeCount(key[0]); count > 0 {
+				key[1] %= count
+			}
+		}
+		keys[part] = key
+	}
+}
+
+// mergeColors 用替换颜色覆盖主题颜色，空字符串表示保留主题颜色
+func mergeColors(colors, override theme.ColorScheme) theme.ColorScheme {
+	if len(override) == 0 {
+		return colors
+	}
+	merged := make(theme.ColorScheme, len(colors))
+	copy(merged, colors)
+	for i, c := range override {
+		if c == "" {
+			continue
+		}
+		if i < len(merged) {
+			merged[i] = c
+		} else {
+			merged = append(merged, c)
+		}
+	}
+	return merged
+}
@@ -1,11 +1,14 @@
 package pixelnebula
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"hash"
-	"log"
+	"image"
+	"image/color"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -42,7 +45,7 @@ var (
 	// 使用分片锁减少锁竞争
 	keyCacheShards    = 16 // 分片数量
 	keyCacheLocks     = make([]sync.RWMutex, keyCacheShards)
-	keyCacheShardData = make([]map[string][2]int, keyCacheShards)
+	keyCacheShardData = make([]map[string]int64, keyCacheShards) // 只缓存哈希数字，风格和主题数量可能变化，索引每次重新计算
 	// 使用对象池减少内存分配
 	builderPool = sync.Pool{
 		New: func() interface{} {
@@ -65,7 +68,7 @@ var (
 func init() {
 	// 初始化分片缓存
 	for i := 0; i < keyCacheShards; i++ {
-		keyCacheShardData[i] = make(map[string][2]int)
+		keyCacheShardData[i] = make(map[string]int64)
 	}
 }
 
@@ -79,10 +82,12 @@ func getShardIndex(key string) int {
 }
 
 type PNOptions struct {
-	ThemeIndex      int  // 主题索引
-	StyleIndex      int  // 风格索引
-	ParallelRender  bool // 是否启用并行渲染
-	ConcurrencyPool int  // 并发池大小，默认为CPU核心数
+	ThemeIndex      int                                   // 主题索引
+	StyleIndex      int                                   // 风格索引
+	ParallelRender  bool                                  // 是否启用并行渲染
+	ConcurrencyPool int                                   // 并发池大小，默认为CPU核心数
+	Parts           map[style.ShapeType][2]int            // 固定风格和主题的部分，主题为-1时沿用按ID选择的主题
+	PartColors      map[style.ShapeType]theme.ColorScheme // 替换部分的颜色
 }
 
 type PixelNebula struct {
@@ -92,10 +97,20 @@ type PixelNebula struct {
 	AnimManager  *animation.Manager
 	Cache        *cache.PNCache
 	Hasher       hash.Hash
+	HashFunc     func() hash.Hash          // 创建哈希实例的函数，nil 时使用 sha256.New
+	Namespaced   bool                      // 是否使用命名空间密钥计算哈希，设置后默认的无障碍标题不包含ID
+	Selection    SelectionVersion          // 部件选择算法版本，0 等同于 SelectionV1
+	PartStyles   map[style.ShapeType][]int // 各部分允许使用的风格索引，未设置的部分不受限制
 	Options      *PNOptions
 	Width        int
 	Height       int
-	ImgData      []byte
+	Padding      int            // 四周留白（像素）
+	AspectRatio  string         // preserveAspectRatio，为空时使用 xMidYMid meet
+	Logger       *slog.Logger   // 日志记录器，nil 时不输出日志
+	Metadata     MetadataFormat // 嵌入SVG的元数据格式，为空时不嵌入
+	// Deprecated: 生成的SVG不再写入实例，并发生成时会互相覆盖，使用 SVGBuilder.ToSVG 获取
+	ImgData []byte
+	err     error // 链式配置方法记录的错误，生成时返回
 }
 
 // NewPixelNebula 创建一个PixelNebula实例
@@ -112,20 +127,48 @@ func NewPixelNebula() *PixelNebula {
 	}
 }
 
-// getSvgStart 根据当前宽高生成SVG开始标签
+// Err 获取链式配置方法记录的错误，没有错误时返回 nil
+// 记录了错误后，Generate 和 GenerateBatch 都返回该错误
+func (pn *PixelNebula) Err() error {
+	return pn.err
+}
+
+// setErr 记录链式配置方法的错误，保留之前记录的错误
+func (pn *PixelNebula) setErr(err error) *PixelNebula {
+	pn.err = errors.Join(pn.err, err)
+	return pn
+}
+
+// getSvgStart 生成画布的SVG开始标签，输出尺寸由 applyLayout 设置
 func (pn *PixelNebula) getSvgStart() string {
-	return fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 %d %d\">", pn.Width, pn.Height)
+	return artworkSVGStart
+}
+
+// discardLogger 未设置日志记录器时使用，不输出任何日志
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// logger 获取日志记录器，未设置时返回不输出日志的记录器
+func (pn *PixelNebula) logger() *slog.Logger {
+	if pn.Logger != nil {
+		return pn.Logger
+	}
+	return discardLogger
+}
+
+// layout 获取实例的输出尺寸设置
+func (pn *PixelNebula) layout() layout {
+	return layout{width: pn.Width, height: pn.Height, padding: pn.Padding, aspect: pn.AspectRatio}
 }
 
 // WithTheme 设置固定主题
+// 主题索引无效时记录错误且不修改设置，见 Err
 func (pn *PixelNebula) WithTheme(themeIndex int) *PixelNebula {
 	// 如果已设置 style,则验证主题索引是否有效
 	if styleIndex := pn.Options.StyleIndex; styleIndex >= 0 {
 		// 获取该风格下的主题数量
 		themeCount := pn.ThemeManager.ThemeCount(styleIndex)
 		if themeIndex < 0 || themeIndex >= themeCount {
-			log.Printf("pixelnebula: theme index range is:[0, %d), but got %d", themeCount, themeIndex)
-			panic(errors.ErrInvalidTheme)
+			return pn.setErr(&errors.IndexError{Kind: errors.IndexTheme, Got: themeIndex, Max: themeCount - 1, Style: styleIndex})
 		}
 	}
 	pn.Options.ThemeIndex = themeIndex
@@ -133,10 +176,11 @@ func (pn *PixelNebula) WithTheme(themeIndex int) *PixelNebula {
 }
 
 // WithStyle 设置固定风格
+// 风格不存在时记录错误且不修改设置，见 Err
 func (pn *PixelNebula) WithStyle(style style.StyleType) *PixelNebula {
 	styleIndex, err := pn.StyleManager.GetStyleIndex(style)
 	if err != nil {
-		panic(err)
+		return pn.setErr(fmt.Errorf("%w: %q", err, style))
 	}
 	pn.Options.StyleIndex = styleIndex
 	return pn
@@ -149,6 +193,31 @@ func (pn *PixelNebula) WithSize(width, height int) *PixelNebula {
 	return pn
 }
 
+// WithPadding 设置头像四周的留白（像素）
+func (pn *PixelNebula) WithPadding(padding int) *PixelNebula {
+	pn.Padding = padding
+	return pn
+}
+
+// WithPreserveAspectRatio 设置宽高比不一致时的对齐方式，如 "xMidYMid meet"、"xMinYMid slice" 或 "none"
+// 取值无效时记录错误且不修改设置，见 Err
+func (pn *PixelNebula) WithPreserveAspectRatio(value string) *PixelNebula {
+	if _, _, err := parseAspectRatio(value); err != nil {
+		return pn.setErr(fmt.Errorf("%w: %q", err, value))
+	}
+	pn.AspectRatio = value
+	return pn
+}
+
+// WithLogger 设置日志记录器，同时作用于缓存和缓存监控器，nil 表示不输出日志
+func (pn *PixelNebula) WithLogger(logger *slog.Logger) *PixelNebula {
+	pn.Logger = logger
+	if pn.Cache != nil {
+		pn.Cache.SetLogger(logger)
+	}
+	return pn
+}
+
 // WithCustomizeTheme 设置自定义主题
 func (pn *PixelNebula) WithCustomizeTheme(theme []theme.Theme) *PixelNebula {
 	pn.ThemeManager.CustomizeTheme(theme)
@@ -187,18 +256,22 @@ func (pn *PixelNebula) hashToNum(hash []string) int64 {
 	return result
 }
 
-// getCacheKey 生成缓存键的哈希表示
-func (pn *PixelNebula) getCacheKey(id string, hash []string, index int, opts *PNOptions) string {
-	// 构造一个唯一的键字符串
-	var key string
-	if opts != nil && opts.StyleIndex >= 0 && opts.ThemeIndex >= 0 {
-		key = fmt.Sprintf("%s_%d_%d_%d", id, index, opts.StyleIndex, opts.ThemeIndex)
-	} else if len(hash) > 0 {
-		key = fmt.Sprintf("%s_%d_%s", id, index, strings.Join(hash, ""))
-	} else {
-		key = fmt.Sprintf("%s_%d", id, index)
+// hashDigits 计算avatarId的哈希值，返回用于选择各部分的数字序列
+func (pn *PixelNebula) hashDigits(id string) ([]string, error) {
+	// 使用对象池获取缓冲区
+	hashBuf := hashBufPool.Get().(*[]byte)
+	defer hashBufPool.Put(hashBuf)
+
+	// 计算avatarId的哈希值 - 优化版本
+	pn.Hasher.Reset()
+	pn.Hasher.Write([]byte(id))
+	sum := pn.Hasher.Sum((*hashBuf)[:0])
+	s := hex.EncodeToString(sum)
+	hashStr := numberRegex.FindAllString(s, -1)
+	if len(hashStr) < hashLength {
+		return nil, errors.ErrInsufficientHash
 	}
-	return key
+	return hashStr[0:hashLength], nil
 }
 
 // calcKey 计算主题和部分的键值
@@ -214,16 +287,17 @@ func (pn *PixelNebula) calcKey(hash []string, opts *PNOptions) [2]int {
 	// 计算分片索引
 	shardIndex := getShardIndex(cacheKey)
 
-	// 尝试从缓存中获取结果，使用读锁
+	// 尝试从缓存中获取哈希值，使用读锁
 	keyCacheLocks[shardIndex].RLock()
-	if result, ok := keyCacheShardData[shardIndex][cacheKey]; ok {
-		keyCacheLocks[shardIndex].RUnlock()
-		return result
-	}
+	hashNum, ok := keyCacheShardData[shardIndex][cacheKey]
 	keyCacheLocks[shardIndex].RUnlock()
-
-	// 计算哈希值
-	hashNum := pn.hashToNum(hash)
+	if !ok {
+		// 计算哈希值并存入缓存，使用写锁
+		hashNum = pn.hashToNum(hash)
+		keyCacheLocks[shardIndex].Lock()
+		keyCacheShardData[shardIndex][cacheKey] = hashNum
+		keyCacheLocks[shardIndex].Unlock()
+	}
 
 	// 获取可用的风格数量
 	styleCount := pn.ThemeManager.StyleCount()
@@ -249,18 +323,13 @@ func (pn *PixelNebula) calcKey(hash []string, opts *PNOptions) [2]int {
 		themeIndex = -themeIndex
 	}
 
-	// 将结果存入缓存，使用写锁
-	result := [2]int{styleIndex, themeIndex}
-	keyCacheLocks[shardIndex].Lock()
-	keyCacheShardData[shardIndex][cacheKey] = result
-	keyCacheLocks[shardIndex].Unlock()
-
-	return result
+	return [2]int{styleIndex, themeIndex}
 }
 
 // WithCache 设置缓存选项
 func (pn *PixelNebula) WithCache(options cache.CacheOptions) *PixelNebula {
 	pn.Cache = cache.NewCache(options)
+	pn.Cache.SetLogger(pn.Logger)
 	// 确保启动监控器
 	if pn.Cache != nil && options.Monitoring.Enabled && pn.Cache.GetMonitor() == nil {
 		pn.Cache.Monitor = cache.NewMonitor(pn.Cache, options.Monitoring)
@@ -272,6 +341,7 @@ func (pn *PixelNebula) WithCache(options cache.CacheOptions) *PixelNebula {
 // WithDefaultCache 设置默认缓存选项
 func (pn *PixelNebula) WithDefaultCache() *PixelNebula {
 	pn.Cache = cache.NewDefaultCache()
+	pn.Cache.SetLogger(pn.Logger)
 	// 确保启动监控器
 	if pn.Cache != nil && pn.Cache.GetOptions().Monitoring.Enabled && pn.Cache.GetMonitor() == nil {
 		pn.Cache.Monitor = cache.NewMonitor(pn.Cache, pn.Cache.GetOptions().Monitoring)
@@ -383,6 +453,19 @@ func (pn *PixelNebula) WithBlinkAnimation(targetID string, minOpacity, maxOpacit
 	return pn
 }
 
+// WithAnimationMode 设置动画输出模式，animation.ModeCSS 使用 @keyframes 代替SMIL动画元素
+func (pn *PixelNebula) WithAnimationMode(mode animation.Mode) *PixelNebula {
+	pn.AnimManager.SetMode(mode)
+	return pn
+}
+
+// WithReducedMotion 设置是否响应 prefers-reduced-motion 媒体查询
+// 启用后动画以CSS模式输出，用户要求减弱动态效果时停用动画，或改为播放动画通过 SetReduced 设置的替代动画
+func (pn *PixelNebula) WithReducedMotion(enabled bool) *PixelNebula {
+	pn.AnimManager.SetReducedMotion(enabled)
+	return pn
+}
+
 // WithParallelRender 启用并行渲染
 func (pn *PixelNebula) WithParallelRender(enabled bool) *PixelNebula {
 	pn.Options.ParallelRender = enabled
@@ -409,6 +492,24 @@ type SVGBuilder struct {
 	width      int
 	height     int
 	hasError   error
+	accessible bool                                  // 是否输出无障碍信息
+	title      string                                // 无障碍标题，同时作为 aria-label
+	desc       string                                // 无障碍描述
+	idPrefix   string                                // 元素ID前缀，用于在同一页面内联多个头像
+	padding    int                                   // 四周留白（像素）
+	aspect     string                                // preserveAspectRatio
+	mask       MaskShape                             // 裁剪形状
+	background BackgroundMode                        // 背景模式
+	bgColor    string                                // 背景颜色或渐变、图案的基础色
+	parts      map[style.ShapeType][2]int            // 固定风格和主题的部分
+	partColors map[style.ShapeType]theme.ColorScheme // 替换颜色的部分
+	metadata   MetadataFormat                        // 嵌入的元数据格式
+	animations []animation.Animation                 // 只作用于本次生成的动画
+	animMode   animation.Mode                        // 本次生成的动画输出模式，为空时使用实例的设置
+	reduced    *bool                                 // 本次生成是否响应 prefers-reduced-motion，nil 时使用实例的设置
+	parallel   *bool                                 // 本次生成是否并行渲染各部分，nil 时使用实例的设置
+	pool       int                                   // 本次生成的并发池大小，0 时使用实例的设置
+	ctx        context.Context                       // 取消后停止生成，nil 表示不可取消
 }
 
 // Generate 现在返回 SVGBuilder
@@ -419,8 +520,12 @@ func (pn *PixelNebula) Generate(id string, sansEnv bool) *SVGBuilder {
 		sansEnv:    sansEnv,
 		width:      pn.Width,
 		height:     pn.Height,
+		padding:    pn.Padding,
+		aspect:     pn.AspectRatio,
 		themeIndex: pn.Options.ThemeIndex,
 		styleIndex: pn.Options.StyleIndex,
+		metadata:   pn.Metadata,
+		hasError:   pn.err,
 	}
 }
 
@@ -431,8 +536,7 @@ func (sb *SVGBuilder) SetTheme(theme int) *SVGBuilder {
 	}
 	themeCount := sb.pn.ThemeManager.ThemeCount(sb.styleIndex)
 	if theme < 0 || theme >= themeCount {
-		log.Printf("pixelnebula: theme index range is:[0, %d), but got %d", themeCount, theme)
-		sb.hasError = errors.ErrInvalidTheme
+		sb.hasError = &errors.IndexError{Kind: errors.IndexTheme, Got: theme, Max: themeCount - 1, Style: sb.styleIndex}
 		return sb
 	}
 	sb.themeIndex = theme
@@ -460,10 +564,9 @@ func (sb *SVGBuilder) SetStyleByIndex(index int) *SVGBuilder {
 	if sb.hasError != nil {
 		return sb
 	}
-	themeCount := sb.pn.ThemeManager.StyleCount()
-	if index < 0 || index >= themeCount {
-		log.Printf("pixelnebula: style index range is:[0, %d), but got %d", themeCount, index)
-		sb.hasError = errors.ErrInvalidStyleName
+	styleCount := sb.pn.ThemeManager.StyleCount()
+	if index < 0 || index >= styleCount {
+		sb.hasError = &errors.IndexError{Kind: errors.IndexStyle, Got: index, Max: styleCount - 1, Style: -1}
 		return sb
 	}
 	sb.styleIndex = index
@@ -480,12 +583,34 @@ func (sb *SVGBuilder) SetSize(width, height int) *SVGBuilder {
 	return sb
 }
 
-// SetAnimation 添加动画效果
+// SetPadding 设置头像四周的留白（像素）
+func (sb *SVGBuilder) SetPadding(padding int) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	sb.padding = padding
+	return sb
+}
+
+// SetPreserveAspectRatio 设置宽高比不一致时的对齐方式，如 "xMidYMid meet"、"xMinYMid slice" 或 "none"
+func (sb *SVGBuilder) SetPreserveAspectRatio(value string) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	if _, _, err := parseAspectRatio(value); err != nil {
+		sb.hasError = err
+		return sb
+	}
+	sb.aspect = value
+	return sb
+}
+
+// SetAnimation 添加只作用于本次生成的动画效果，生成时与实例的动画合并
 func (sb *SVGBuilder) SetAnimation(anim animation.Animation) *SVGBuilder {
 	if sb.hasError != nil {
 		return sb
 	}
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
 	return sb
 }
 
@@ -495,7 +620,7 @@ func (sb *SVGBuilder) SetRotateAnimation(targetID string, fromAngle, toAngle flo
 		return sb
 	}
 	anim := animation.NewRotateAnimation(targetID, fromAngle, toAngle, duration, repeatCount)
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
 	return sb
 }
 
@@ -505,7 +630,7 @@ func (sb *SVGBuilder) SetGradientAnimation(targetID string, colors []string, dur
 		return sb
 	}
 	anim := animation.NewGradientAnimation(targetID, colors, duration, repeatCount, animate)
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
 	return sb
 }
 
@@ -515,7 +640,7 @@ func (sb *SVGBuilder) SetTransformAnimation(targetID string, transformType strin
 		return sb
 	}
 	anim := animation.NewTransformAnimation(targetID, transformType, from, to, duration, repeatCount)
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
 	return sb
 }
 
@@ -525,7 +650,7 @@ func (sb *SVGBuilder) SetFadeAnimation(targetID string, from, to string, duratio
 		return sb
 	}
 	anim := animation.NewFadeAnimation(targetID, from, to, duration, repeatCount)
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
 	return sb
 }
 
@@ -535,7 +660,7 @@ func (sb *SVGBuilder) SetPathAnimation(targetID string, path string, duration fl
 		return sb
 	}
 	anim := animation.NewPathAnimation(targetID, path, duration, repeatCount)
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
 	return sb
 }
 
@@ -546,7 +671,7 @@ func (sb *SVGBuilder) SetPathAnimationRotate(targetID string, path string, rotat
 	}
 	anim := animation.NewPathAnimation(targetID, path, duration, repeatCount)
 	anim.WithRotate(rotate)
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
 	return sb
 }
 
@@ -556,7 +681,7 @@ func (sb *SVGBuilder) SetColorAnimation(targetID string, property string, fromCo
 		return sb
 	}
 	anim := animation.NewColorAnimation(targetID, property, fromColor, toColor, duration, repeatCount)
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
 	return sb
 }
 
@@ -566,7 +691,7 @@ func (sb *SVGBuilder) SetBounceAnimation(targetID string, property string, from,
 		return sb
 	}
 	anim := animation.NewBounceAnimation(targetID, property, from, to, bounceCount, duration, repeatCount)
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
 	return sb
 }
 
@@ -576,7 +701,7 @@ func (sb *SVGBuilder) SetWaveAnimation(targetID string, amplitude, frequency flo
 		return sb
 	}
 	anim := animation.NewWaveAnimation(targetID, amplitude, frequency, direction, duration, repeatCount)
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
 	return sb
 }
 
@@ -586,7 +711,25 @@ func (sb *SVGBuilder) SetBlinkAnimation(targetID string, minOpacity, maxOpacity
 		return sb
 	}
 	anim := animation.NewBlinkAnimation(targetID, minOpacity, maxOpacity, blinkCount, duration, repeatCount)
-	sb.pn.AnimManager.AddAnimation(anim)
+	sb.animations = append(sb.animations, anim)
+	return sb
+}
+
+// SetAnimationMode 设置动画输出模式，animation.ModeCSS 使用 @keyframes 代替SMIL动画元素
+func (sb *SVGBuilder) SetAnimationMode(mode animation.Mode) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	sb.animMode = mode
+	return sb
+}
+
+// SetReducedMotion 设置是否响应 prefers-reduced-motion 媒体查询
+func (sb *SVGBuilder) SetReducedMotion(enabled bool) *SVGBuilder {
+	if sb.hasError != nil {
+		return sb
+	}
+	sb.reduced = &enabled
 	return sb
 }
 
@@ -595,7 +738,7 @@ func (sb *SVGBuilder) SetParallelRender(enabled bool) *SVGBuilder {
 	if sb.hasError != nil {
 		return sb
 	}
-	sb.pn.Options.ParallelRender = enabled
+	sb.parallel = &enabled
 	return sb
 }
 
@@ -607,31 +750,124 @@ func (sb *SVGBuilder) SetConcurrencyPool(size int) *SVGBuilder {
 	if size <= 0 {
 		size = runtime.NumCPU()
 	}
-	sb.pn.Options.ConcurrencyPool = size
+	sb.pool = size
 	return sb
 }
 
+// options 获取生成画布使用的选项，未在构建器上设置的并发选项使用实例的设置
+func (sb *SVGBuilder) options() *PNOptions {
+	opts := &PNOptions{
+		ThemeIndex:      sb.themeIndex,
+		StyleIndex:      sb.styleIndex,
+		Parts:           sb.parts,
+		PartColors:      sb.partColors,
+		ParallelRender:  sb.pn.Options.ParallelRender,
+		ConcurrencyPool: sb.pn.Options.ConcurrencyPool,
+	}
+	if sb.parallel != nil {
+		opts.ParallelRender = *sb.parallel
+	}
+	if sb.pool > 0 {
+		opts.ConcurrencyPool = sb.pool
+	}
+	return opts
+}
+
+// animManager 获取本次生成使用的动画管理器
+// 没有设置本次生成的动画时直接使用实例的管理器，否则在实例管理器的副本上追加
+func (sb *SVGBuilder) animManager() *animation.Manager {
+	if len(sb.animations) == 0 && sb.animMode == "" && sb.reduced == nil {
+		return sb.pn.AnimManager
+	}
+	m := sb.pn.AnimManager.Clone()
+	for _, anim := range sb.animations {
+		m.AddAnimation(anim)
+	}
+	if sb.animMode != "" {
+		m.SetMode(sb.animMode)
+	}
+	if sb.reduced != nil {
+		m.SetReducedMotion(*sb.reduced)
+	}
+	return m
+}
+
 // Build 生成最终的SVG
 func (sb *SVGBuilder) Build() *SVGBuilder {
 	if sb.hasError != nil {
 		return sb
 	}
 
-	opts := &PNOptions{
-		ThemeIndex: sb.themeIndex,
-		StyleIndex: sb.styleIndex,
+	opts := sb.options()
+
+	pn := sb.pn
+	if anims := sb.animManager(); anims != sb.pn.AnimManager {
+		// 使用本次生成的动画，缓存键包含动画，可以共用缓存
+		scoped := *sb.pn
+		scoped.AnimManager = anims
+		pn = &scoped
+	}
+
+	ctx := sb.ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
 
-	svg, err := sb.pn.generateSVG(sb.id, sb.sansEnv, opts)
+	// 透明背景与不含背景的画布相同
+	sansEnv := sb.sansEnv || sb.background == BackgroundTransparent
+	svg, err := pn.generateSVG(ctx, sb.id, sansEnv, opts)
+	if err == nil {
+		// 画布之后的处理较快，只在开始前检查一次
+		err = ctx.Err()
+	}
 	if err != nil {
 		sb.hasError = err
 		return sb
 	}
 
+	if !sansEnv {
+		svg, err = applyBackground(svg, sb.background, sb.bgColor)
+		if err != nil {
+			sb.hasError = err
+			return sb
+		}
+	}
+
+	if sb.mask != MaskNone {
+		svg, err = applyMask(svg, sb.mask)
+		if err != nil {
+			sb.hasError = err
+			return sb
+		}
+	}
+
+	svg, err = applyLayout(svg, layout{width: sb.width, height: sb.height, padding: sb.padding, aspect: sb.aspect})
+	if err != nil {
+		sb.hasError = err
+		return sb
+	}
+
+	if sb.idPrefix != "" {
+		svg = prefixIDs(svg, sb.idPrefix)
+	}
+
+	if sb.accessible {
+		svg, err = sb.addAccessibility(svg)
+		if err != nil {
+			sb.hasError = err
+			return sb
+		}
+	}
+
+	if sb.metadata != MetadataNone {
+		svg, err = sb.addMetadata(svg)
+		if err != nil {
+			sb.hasError = err
+			return sb
+		}
+	}
+
 	sb.svg = svg
-	sb.pn.ImgData = []byte(svg)
-	sb.pn.Width = sb.width
-	sb.pn.Height = sb.height
 	return sb
 }
 
@@ -646,7 +882,7 @@ func (sb *SVGBuilder) ToSVG() (string, error) {
 	return sb.svg, nil
 }
 
-// ToBase64 获取Base64编码的SVG字符串 注意：这个设置宽高无效
+// ToBase64 获取Base64编码的SVG字符串
 func (sb *SVGBuilder) ToBase64() (string, error) {
 	if sb.svg == "" {
 		sb = sb.Build()
@@ -658,6 +894,70 @@ func (sb *SVGBuilder) ToBase64() (string, error) {
 	return conv.ToBase64()
 }
 
+// ToStaticSVG 获取动画在时刻t（秒）的静态快照，所有动画属性被替换为该时刻的计算值
+// 可用于生成缩略图的封面帧，或作为不支持SMIL的渲染器的静态回退
+func (sb *SVGBuilder) ToStaticSVG(t float64) (string, error) {
+	if sb.svg == "" {
+		sb = sb.Build()
+	}
+	if sb.hasError != nil {
+		return "", sb.hasError
+	}
+	if t < 0 {
+		t = 0
+	}
+	return snapshotSVG(sb.svg, sb.animManager().GetAnimations(), t, sb.idPrefix)
+}
+
+// ToPNG 获取PNG格式的图像数据，按SVGBuilder的宽高在动画起始时刻进行栅格化
+func (sb *SVGBuilder) ToPNG() ([]byte, error) {
+	if sb.svg == "" {
+		sb = sb.Build()
+	}
+	if sb.hasError != nil {
+		return nil, sb.hasError
+	}
+	svg, err := snapshotSVG(sb.svg, sb.animManager().GetAnimations(), 0, sb.idPrefix)
+	if err != nil {
+		return nil, err
+	}
+	conv := converter.NewSVGConverter([]byte(svg), sb.width, sb.height)
+	return conv.ToPNG()
+}
+
+// ToImage 获取栅格化后的RGBA图像，可直接用于image/draw合成
+// scale 为DPI缩放系数，例如2表示输出两倍像素密度的图像
+func (sb *SVGBuilder) ToImage(scale float64) (*image.RGBA, error) {
+	if sb.svg == "" {
+		sb = sb.Build()
+	}
+	if sb.hasError != nil {
+		return nil, sb.hasError
+	}
+	svg, err := snapshotSVG(sb.svg, sb.animManager().GetAnimations(), 0, sb.idPrefix)
+	if err != nil {
+		return nil, err
+	}
+	conv := converter.NewSVGConverter([]byte(svg), sb.width, sb.height).WithScale(scale)
+	return conv.ToImage()
+}
+
+// ToJPEG 获取JPEG格式的图像数据，透明区域使用背景色填充，bg 为 nil 时使用白色
+func (sb *SVGBuilder) ToJPEG(bg color.Color) ([]byte, error) {
+	if sb.svg == "" {
+		sb = sb.Build()
+	}
+	if sb.hasError != nil {
+		return nil, sb.hasError
+	}
+	svg, err := snapshotSVG(sb.svg, sb.animManager().GetAnimations(), 0, sb.idPrefix)
+	if err != nil {
+		return nil, err
+	}
+	conv := converter.NewSVGConverter([]byte(svg), sb.width, sb.height).WithBackground(bg)
+	return conv.ToJPEG()
+}
+
 // ToFile 将SVG代码保存到文件
 func (sb *SVGBuilder) ToFile(filePath string) error {
 	if sb.svg == "" {
@@ -670,7 +970,9 @@ func (sb *SVGBuilder) ToFile(filePath string) error {
 }
 
 // 将原来的 GenerateSVG 重命名为 generateSVG，作为内部方法
-func (pn *PixelNebula) generateSVG(id string, sansEnv bool, opts *PNOptions) (svg string, err error) {
+// 缓存中保存的是画布，尺寸、背景等之后的处理设置不计入缓存键
+// ctx 取消后不再渲染剩余的部分
+func (pn *PixelNebula) generateSVG(ctx context.Context, id string, sansEnv bool, opts *PNOptions) (svg string, err error) {
 	if opts == nil {
 		opts = pn.Options
 	}
@@ -679,38 +981,20 @@ func (pn *PixelNebula) generateSVG(id string, sansEnv bool, opts *PNOptions) (sv
 		return "", errors.ErrAvatarIDRequired
 	}
 
+	keys, err := pn.partKeys(id, opts)
+	if err != nil {
+		return "", err
+	}
+
 	// 如果启用了缓存，先尝试从缓存获取
+	var cacheKey cache.CacheKey
 	if pn.Cache != nil {
-		cacheKey := cache.CacheKey{
-			Id:      id,
-			SansEnv: sansEnv,
-		}
-
-		if opts != nil {
-			cacheKey.Theme = opts.ThemeIndex
-			cacheKey.Part = opts.StyleIndex
-		}
-
+		cacheKey = pn.cacheKey(id, sansEnv, opts, keys)
 		if cachedSVG, found := pn.Cache.Get(cacheKey); found {
 			return cachedSVG, nil
 		}
 	}
 
-	// 使用对象池获取缓冲区
-	hashBuf := hashBufPool.Get().(*[]byte)
-	defer hashBufPool.Put(hashBuf)
-
-	// 计算avatarId的哈希值 - 优化版本
-	pn.Hasher.Reset()
-	pn.Hasher.Write([]byte(id))
-	sum := pn.Hasher.Sum((*hashBuf)[:0])
-	s := hex.EncodeToString(sum)
-	hashStr := numberRegex.FindAllString(s, -1)
-	if len(hashStr) < hashLength {
-		return "", errors.ErrInsufficientHash
-	}
-	hashStr = hashStr[0:hashLength]
-
 	// 从对象池获取映射
 	p := keyMapPool.Get().(map[string][2]int)
 	defer func() {
@@ -721,13 +1005,10 @@ func (pn *PixelNebula) generateSVG(id string, sansEnv bool, opts *PNOptions) (sv
 		keyMapPool.Put(p)
 	}()
 
-	// 计算各部分的键值
-	p[string(style.TypeEnv)] = pn.calcKey(hashStr[:2], opts)
-	p[string(style.TypeClo)] = pn.calcKey(hashStr[2:4], opts)
-	p[string(style.TypeHead)] = pn.calcKey(hashStr[4:6], opts)
-	p[string(style.TypeMouth)] = pn.calcKey(hashStr[6:8], opts)
-	p[string(style.TypeEyes)] = pn.calcKey(hashStr[8:10], opts)
-	p[string(style.TypeTop)] = pn.calcKey(hashStr[10:], opts)
+	// 各部分的键值
+	for part, key := range keys {
+		p[string(part)] = key
+	}
 
 	// 获取结果映射
 	final := mapPool.Get().(map[string]string)
@@ -752,66 +1033,17 @@ func (pn *PixelNebula) generateSVG(id string, sansEnv bool, opts *PNOptions) (sv
 			wg.Add(1)
 			go func(key string, val [2]int) {
 				defer wg.Done()
-
-				// 使用临时变量处理这个部分
-				tempResult := ""
-
-				// 获取主题颜色
-				themePart, err := pn.ThemeManager.GetTheme(val[0], val[1])
-				if err != nil {
+				if err := ctx.Err(); err != nil {
 					errChan <- err
 					return
 				}
 
-				colors, ok := themePart[key]
-				if !ok {
-					errChan <- errors.ErrInvalidColor
-					return
-				}
-
-				// 获取形状SVG
-				shapeType := style.ShapeType(key)
-				svgPart, err := pn.StyleManager.GetShape(val[0], shapeType)
+				tempResult, err := pn.renderPart(key, val, opts.PartColors[style.ShapeType(key)])
 				if err != nil {
 					errChan <- err
 					return
 				}
 
-				match := colorRegex.FindAllStringSubmatch(svgPart, -1)
-
-				// 从对象池获取Builder
-				sb := builderPool.Get().(*strings.Builder)
-				sb.Reset()
-				sb.Grow(len(svgPart) + 50) // 预分配足够的容量
-
-				lastIndex := 0
-				for i, m := range match {
-					if i < len(colors) {
-						// 找到完整匹配的位置
-						index := strings.Index(svgPart[lastIndex:], m[0]) + lastIndex
-						// 添加匹配前的部分
-						sb.WriteString(svgPart[lastIndex:index])
-						// 添加替换后的颜色
-						// 检查颜色值是否已经包含#前缀
-						if strings.HasPrefix(colors[i], "#") {
-							sb.WriteString(colors[i])
-						} else {
-							sb.WriteString("#")
-							sb.WriteString(colors[i])
-						}
-						sb.WriteString(";")
-						// 更新lastIndex
-						lastIndex = index + len(m[0])
-					}
-				}
-				// 添加剩余部分
-				sb.WriteString(svgPart[lastIndex:])
-
-				tempResult = sb.String()
-
-				// 归还Builder到对象池
-				builderPool.Put(sb)
-
 				// 使用互斥锁保护对 final map 的写入
 				finalMux.Lock()
 				final[key] = tempResult
@@ -832,7 +1064,10 @@ func (pn *PixelNebula) generateSVG(id string, sansEnv bool, opts *PNOptions) (sv
 	} else {
 		// 串行处理
 		for k, v := range p {
-			if err := pn.processSVGPart(k, v, final); err != nil {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			if err := pn.processSVGPart(k, v, opts.PartColors[style.ShapeType(k)], final); err != nil {
 				return "", err
 			}
 		}
@@ -848,7 +1083,7 @@ func (pn *PixelNebula) generateSVG(id string, sansEnv bool, opts *PNOptions) (sv
 	builder.WriteString(pn.getSvgStart())
 
 	// 获取动画定义
-	animations := pn.AnimManager.GenerateSVGAnimations()
+	animations := pn.AnimManager.Generate()
 	if animations != "" {
 		builder.WriteString(animations)
 	}
@@ -857,9 +1092,9 @@ func (pn *PixelNebula) generateSVG(id string, sansEnv bool, opts *PNOptions) (sv
 	rotateAnimations := make(map[string]bool)
 	rotateAnimationSVGs := make(map[string]string)
 
-	// 收集旋转动画
+	// 收集旋转动画，CSS模式下旋转由样式规则完成，无需包裹元素
 	for _, anim := range pn.AnimManager.GetAnimations() {
-		if rotateAnim, ok := anim.(*animation.RotateAnimation); ok {
+		if rotateAnim, ok := anim.(*animation.RotateAnimation); ok && pn.AnimManager.Mode() != animation.ModeCSS {
 			targetID := anim.GetTargetID()
 			rotateAnimations[targetID] = true
 
@@ -905,24 +1140,11 @@ func (pn *PixelNebula) generateSVG(id string, sansEnv bool, opts *PNOptions) (sv
 	builder.WriteString(pn.SvgEnd)
 	svg = builder.String()
 
-	// 将生成的SVG存储到实例中
-	pn.ImgData = []byte(svg)
-
 	// 归还Builder到对象池
 	builderPool.Put(builder)
 
 	// 如果启用了缓存，将结果存入缓存
 	if pn.Cache != nil {
-		cacheKey := cache.CacheKey{
-			Id:      id,
-			SansEnv: sansEnv,
-		}
-
-		if opts != nil {
-			cacheKey.Theme = opts.ThemeIndex
-			cacheKey.Part = opts.StyleIndex
-		}
-
 		pn.Cache.Set(cacheKey, svg)
 	}
 
@@ -932,7 +1154,7 @@ func (pn *PixelNebula) generateSVG(id string, sansEnv bool, opts *PNOptions) (sv
 // GetCacheStats 获取缓存统计信息
 func (pn *PixelNebula) GetCacheStats() (size, hits, misses int, hitRate float64, enabled bool, maxSize int, expiration time.Duration, evictionType string) {
 	if pn.Cache == nil {
-		log.Println("pixelnebula: 缓存未初始化，请先调用WithCache或WithDefaultCache")
+		pn.logger().Warn("cache not initialized", slog.String("method", "GetCacheStats"))
 		return 0, 0, 0, 0, false, 0, 0, ""
 	}
 
@@ -957,14 +1179,14 @@ func (pn *PixelNebula) GetCacheItems() []CacheItemInfo {
 	var result []CacheItemInfo
 
 	if pn.Cache == nil {
-		log.Println("pixelnebula: 缓存未初始化，请先调用WithCache或WithDefaultCache")
+		pn.logger().Warn("cache not initialized", slog.String("method", "GetCacheItems"))
 		return result
 	}
 
 	// 获取内部缓存项
 	items := pn.Cache.GetAllItems()
 	if len(items) == 0 {
-		log.Println("pixelnebula: 缓存中没有数据，请先生成一些SVG")
+		pn.logger().Debug("cache is empty")
 	}
 
 	for key, item := range items {
@@ -998,7 +1220,7 @@ func (pn *PixelNebula) GetMonitorStats() (enabled bool, sampleInterval, adjustIn
 	targetHitRate float64, lastAdjusted time.Time, samples []MonitorSampleInfo) {
 
 	if pn.Cache == nil {
-		log.Println("pixelnebula: 缓存未初始化，请先调用WithCache或WithDefaultCache")
+		pn.logger().Warn("cache not initialized", slog.String("method", "GetMonitorStats"))
 		return false, 0, 0, 0, time.Time{}, nil
 	}
 
@@ -1006,12 +1228,12 @@ func (pn *PixelNebula) GetMonitorStats() (enabled bool, sampleInterval, adjustIn
 
 	// 确保监控器已启用并初始化
 	if !options.Enabled {
-		log.Println("pixelnebula: 监控未启用，请设置Monitoring.Enabled=true")
+		pn.logger().Warn("cache monitoring disabled")
 		return options.Enabled, options.SampleInterval, options.AdjustInterval, options.TargetHitRate, time.Time{}, nil
 	}
 
 	if pn.Cache.GetMonitor() == nil {
-		log.Println("pixelnebula: 监控器未初始化，正在初始化...")
+		pn.logger().Info("starting cache monitor")
 		pn.Cache.Monitor = cache.NewMonitor(pn.Cache, options)
 		pn.Cache.Monitor.Start()
 	}
@@ -1019,9 +1241,8 @@ func (pn *PixelNebula) GetMonitorStats() (enabled bool, sampleInterval, adjustIn
 	monitor := pn.Cache.GetMonitor()
 	stats := monitor.GetStats()
 	sampleHistory := monitor.GetSampleHistory()
-
 	if len(sampleHistory) == 0 {
-		log.Println("pixelnebula: 监控样本为空，请等待采样完成")
+		pn.logger().Debug("no cache monitor samples yet")
 	}
 
 	// 转换样本历史
@@ -1043,31 +1264,39 @@ func (pn *PixelNebula) GetMonitorStats() (enabled bool, sampleInterval, adjustIn
 }
 
 // DeleteCacheItem 删除指定的缓存项
+// key 为缓存键的字符串形式 "id_sansEnv_theme_part_digest"；
+// 也接受不含摘要的旧格式 "id_sansEnv_theme_part"，此时删除该ID、主题和风格下的所有缓存项
 func (pn *PixelNebula) DeleteCacheItem(key string) bool {
 	if pn.Cache == nil {
-		log.Println("pixelnebula: 缓存未初始化，请先调用WithCache或WithDefaultCache")
+		pn.logger().Warn("cache not initialized", slog.String("method", "DeleteCacheItem"))
 		return false
 	}
 
-	// 解析key字符串，格式为"id_sansEnv_theme_part"
+	// ID中可能包含下划线，从右侧取固定数量的字段，其余部分为ID
+	// 最后一个字段是摘要时为新格式，否则为不含摘要的旧格式
 	parts := strings.Split(key, "_")
-	if len(parts) < 4 {
-		log.Printf("pixelnebula: 无效的key格式: %s，应为'id_sansEnv_theme_part'", key)
+	fields := 3
+	if isDigest(parts[len(parts)-1]) {
+		fields = 4
+	}
+	if len(parts) <= fields {
+		pn.logger().Warn("invalid cache key", slog.String("key", key), slog.String("format", "id_sansEnv_theme_part_digest"))
 		return false
 	}
+	n := len(parts) - fields
+	id := strings.Join(parts[:n], "_")
+	parts = parts[n:]
+	sansEnv := parts[0] == "true"
 
-	id := parts[0]
-	sansEnv := parts[1] == "true"
-
-	themeItem, err := strconv.Atoi(parts[2])
+	themeItem, err := strconv.Atoi(parts[1])
 	if err != nil {
-		log.Printf("pixelnebula: 解析theme失败: %v", err)
+		pn.logger().Warn("invalid cache key", slog.String("key", key), slog.String("field", "theme"), slog.Any("error", err))
 		return false
 	}
 
-	part, err := strconv.Atoi(parts[3])
+	part, err := strconv.Atoi(parts[2])
 	if err != nil {
-		log.Printf("pixelnebula: 解析part失败: %v", err)
+		pn.logger().Warn("invalid cache key", slog.String("key", key), slog.String("field", "part"), slog.Any("error", err))
 		return false
 	}
 
@@ -1079,42 +1308,72 @@ func (pn *PixelNebula) DeleteCacheItem(key string) bool {
 		Part:    part,
 	}
 
-	result := pn.Cache.DeleteItem(cacheKey)
-	if !result {
-		log.Printf("pixelnebula: 未找到缓存项: %s", key)
+	deleted := false
+	if fields == 4 {
+		cacheKey.Digest = parts[3]
+		deleted = pn.Cache.DeleteItem(cacheKey)
+	} else {
+		for k := range pn.Cache.GetAllItems() {
+			if k.Id == id && k.SansEnv == sansEnv && k.Theme == themeItem && k.Part == part {
+				deleted = pn.Cache.DeleteItem(k) || deleted
+			}
+		}
 	}
-	return result
+	if !deleted {
+		pn.logger().Debug("cache item not found", slog.String("key", key))
+		return false
+	}
+	return true
+}
+
+// isDigest 判断字符串是否为 cacheKey 生成的十六进制SHA-256摘要
+func isDigest(s string) bool {
+	if len(s) != 2*sha256.Size {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
 }
 
 // ClearCache 清空缓存
 func (pn *PixelNebula) ClearCache() {
 	if pn.Cache == nil {
-		log.Println("pixelnebula: 缓存未初始化，请先调用WithCache或WithDefaultCache")
+		pn.logger().Warn("cache not initialized", slog.String("method", "ClearCache"))
 		return
 	}
 
 	pn.Cache.Clear()
-	log.Println("pixelnebula: 缓存已清空")
 }
 
 // 将原来的 generateSVG 方法中的部分代码提取为独立函数，方便并行处理
-func (pn *PixelNebula) processSVGPart(k string, v [2]int, final map[string]string) error {
+func (pn *PixelNebula) processSVGPart(k string, v [2]int, override theme.ColorScheme, final map[string]string) error {
+	svgPart, err := pn.renderPart(k, v, override)
+	if err != nil {
+		return err
+	}
+	final[k] = svgPart
+	return nil
+}
+
+// renderPart 获取部分的形状并填入主题颜色，override 中的颜色优先
+func (pn *PixelNebula) renderPart(k string, v [2]int, override theme.ColorScheme) (string, error) {
 	// 获取主题颜色
 	themePart, err := pn.ThemeManager.GetTheme(v[0], v[1])
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	colors, ok := themePart[k]
 	if !ok {
-		return errors.ErrInvalidColor
+		return "", errors.ErrInvalidColor
 	}
+	colors = mergeColors(colors, override)
 
 	// 获取形状SVG
 	shapeType := style.ShapeType(k)
 	svgPart, err := pn.StyleManager.GetShape(v[0], shapeType)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	match := colorRegex.FindAllStringSubmatch(svgPart, -1)
@@ -1147,12 +1406,12 @@ func (pn *PixelNebula) processSVGPart(k string, v [2]int, final map[string]strin
 	// 添加剩余部分
 	sb.WriteString(svgPart[lastIndex:])
 
-	final[k] = sb.String()
+	result := sb.String()
 
 	// 归还Builder到对象池
 	builderPool.Put(sb)
 
-	return nil
+	return result, nil
 }
 
 // GenerateBatch 批量生成SVG图像
@@ -1166,6 +1425,9 @@ func (pn *PixelNebula) GenerateBatch(ids []string, sansEnv bool, opts *PNOptions
 	}
 
 	// 验证参数
+	if pn.err != nil {
+		return nil, pn.err
+	}
 	if len(ids) == 0 {
 		return nil, errors.ErrAvatarIDRequired
 	}
@@ -1176,7 +1438,7 @@ func (pn *PixelNebula) GenerateBatch(ids []string, sansEnv bool, opts *PNOptions
 	// 如果没有启用并发处理，则串行生成
 	if !opts.ParallelRender {
 		for _, id := range ids {
-			svg, err := pn.generateSVG(id, sansEnv, opts)
+			svg, err := pn.batchBuilder(id, sansEnv, opts).ToSVG()
 			if err != nil {
 				return result, err
 			}
@@ -1221,15 +1483,23 @@ func (pn *PixelNebula) GenerateBatch(ids []string, sansEnv bool, opts *PNOptions
 				ThemeManager: pn.ThemeManager, // 这些管理器是安全的，因为它们的方法是并发安全的或只读的
 				StyleManager: pn.StyleManager,
 				AnimManager:  pn.AnimManager,
-				Cache:        pn.Cache,     // 缓存有自己的锁机制
-				Hasher:       sha256.New(), // 创建新的哈希实例，避免并发访问冲突
+				Cache:        pn.Cache,       // 缓存有自己的锁机制
+				Hasher:       pn.newHasher(), // 创建新的哈希实例，避免并发访问冲突
+				HashFunc:     pn.HashFunc,
+				Namespaced:   pn.Namespaced,
 				Options:      opts,
 				Width:        pn.Width,
 				Height:       pn.Height,
+				Padding:      pn.Padding,
+				AspectRatio:  pn.AspectRatio,
+				Logger:       pn.Logger,
+				Selection:    pn.Selection,
+				PartStyles:   pn.PartStyles,
+				Metadata:     pn.Metadata,
 			}
 
 			for id := range tasks {
-				svg, err := workerPN.generateSVG(id, sansEnv, opts)
+				svg, err := workerPN.batchBuilder(id, sansEnv, opts).ToSVG()
 				resultChan <- resultPair{id, svg, err}
 			}
 		}()
@@ -1252,6 +1522,15 @@ func (pn *PixelNebula) GenerateBatch(ids []string, sansEnv bool, opts *PNOptions
 	return result, nil
 }
 
+// batchBuilder 创建批量生成单个ID的构建器，与 Generate 经过相同的处理
+func (pn *PixelNebula) batchBuilder(id string, sansEnv bool, opts *PNOptions) *SVGBuilder {
+	sb := pn.Generate(id, sansEnv)
+	sb.themeIndex, sb.styleIndex = opts.ThemeIndex, opts.StyleIndex
+	sb.parts, sb.partColors = opts.Parts, opts.PartColors
+	sb.parallel, sb.pool = &opts.ParallelRender, opts.ConcurrencyPool
+	return sb
+}
+
 // SaveBatchToFiles 批量保存SVG到文件
 // ids：要生成的ID列表
 // sansEnv：是否不包含环境
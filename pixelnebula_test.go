@@ -1,11 +1,33 @@
 package pixelnebula
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"github.com/landaiqing/go-pixelnebula/animation"
+	"github.com/landaiqing/go-pixelnebula/cache"
+	"github.com/landaiqing/go-pixelnebula/converter"
+	"github.com/landaiqing/go-pixelnebula/errors"
 	"github.com/landaiqing/go-pixelnebula/style"
+	"github.com/landaiqing/go-pixelnebula/theme"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"math"
 	"os"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -235,3 +257,1525 @@ func TestPathAnimation(t *testing.T) {
 		os.Exit(1)
 	}
 }
+
+// 测试PNG和JPEG栅格化输出
+func TestRasterOutput(t *testing.T) {
+	pn := NewPixelNebula()
+	pn.WithStyle(style.GuyStyle)
+	pn.WithTheme(1)
+
+	pngData, err := pn.Generate("raster-id", false).SetSize(128, 128).ToPNG()
+	if err != nil {
+		t.Fatalf("生成PNG失败: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(pngData))
+	if err != nil {
+		t.Fatalf("解码PNG失败: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 128 || b.Dy() != 128 {
+		t.Errorf("PNG尺寸错误: %v", b)
+	}
+	// 圆形背景之外应为透明，中心应为不透明
+	if _, _, _, a := img.At(1, 1).RGBA(); a != 0 {
+		t.Errorf("PNG角落应为透明, alpha=%d", a)
+	}
+	if _, _, _, a := img.At(64, 64).RGBA(); a != 0xffff {
+		t.Errorf("PNG中心应为不透明, alpha=%d", a)
+	}
+
+	jpegData, err := pn.Generate("raster-id", false).SetSize(64, 64).ToJPEG(color.White)
+	if err != nil {
+		t.Fatalf("生成JPEG失败: %v", err)
+	}
+	img, err = jpeg.Decode(bytes.NewReader(jpegData))
+	if err != nil {
+		t.Fatalf("解码JPEG失败: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 64 {
+		t.Errorf("JPEG尺寸错误: %v", b)
+	}
+	if r, g, b, _ := img.At(0, 0).RGBA(); r>>8 < 0xf0 || g>>8 < 0xf0 || b>>8 < 0xf0 {
+		t.Errorf("JPEG角落应为背景色, got %d %d %d", r>>8, g>>8, b>>8)
+	}
+}
+
+// 测试输出RGBA图像并合成到其他图像上
+func TestToImage(t *testing.T) {
+	pn := NewPixelNebula()
+	pn.WithStyle(style.GirlStyle)
+	pn.WithTheme(0)
+
+	img, err := pn.Generate("image-id", false).SetSize(48, 48).ToImage(2)
+	if err != nil {
+		t.Fatalf("生成图像失败: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 96 || b.Dy() != 96 {
+		t.Errorf("图像尺寸错误, 期望 96x96, 实际 %v", b)
+	}
+
+	// 合成到一个更大的画布上
+	canvas := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	draw.Draw(canvas, image.Rect(100, 0, 196, 96), img, image.Point{}, draw.Over)
+	if _, _, _, a := canvas.At(148, 48).RGBA(); a == 0 {
+		t.Errorf("合成后头像区域不应为透明")
+	}
+
+	// 过大的尺寸应返回错误，而不是分配巨大的图像
+	if _, err := pn.Generate("image-id", false).SetSize(4096, 4096).ToImage(1e6); !errors.Is(err, errors.ErrInvalidSize) {
+		t.Errorf("超出最大尺寸应返回 ErrInvalidSize, 实际 %v", err)
+	}
+}
+
+// TestAnimatedExport 测试按动画时间轴导出GIF和APNG
+func TestAnimatedExport(t *testing.T) {
+	pn := NewPixelNebula()
+	pn.WithStyle(style.GirlStyle)
+	pn.WithTheme(0)
+	pn.WithSize(64, 64)
+	pn.WithRotateAnimation("env", 0, 360, 2, -1)
+	pn.WithFadeAnimation("eyes", "1", "0.2", 1, -1)
+
+	gifData, err := pn.Generate("animated-id", false).ToGIF(&FrameOptions{Frames: 8})
+	if err != nil {
+		t.Fatalf("导出GIF失败: %v", err)
+	}
+	anim, err := gif.DecodeAll(bytes.NewReader(gifData))
+	if err != nil {
+		t.Fatalf("解码GIF失败: %v", err)
+	}
+	if len(anim.Image) != 8 {
+		t.Errorf("GIF帧数错误, 期望 8, 实际 %d", len(anim.Image))
+	}
+	if anim.Delay[0] != 25 {
+		t.Errorf("GIF帧延迟错误, 期望 25, 实际 %d", anim.Delay[0])
+	}
+	if bytes.Equal(anim.Image[0].Pix, anim.Image[2].Pix) {
+		t.Errorf("不同时刻的帧不应相同")
+	}
+
+	apngData, err := pn.Generate("animated-id", false).ToAPNG(&FrameOptions{Frames: 4, LoopCount: 1})
+	if err != nil {
+		t.Fatalf("导出APNG失败: %v", err)
+	}
+	// 不支持APNG的解码器应能读取第一帧
+	img, err := png.Decode(bytes.NewReader(apngData))
+	if err != nil {
+		t.Fatalf("解码APNG失败: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 64 {
+		t.Errorf("APNG尺寸错误, 期望 64x64, 实际 %v", b)
+	}
+	if bytes.Count(apngData, []byte("fcTL")) != 4 || !bytes.Contains(apngData, []byte("acTL")) {
+		t.Errorf("APNG动画控制块错误")
+	}
+
+	// 没有可绘制的形状时包围盒为空矩形
+	minX, minY, maxX, maxY, err := converter.Bounds([]byte(`<g id="env"><path d=""/></g>`))
+	if err != nil || minX != 0 || minY != 0 || maxX != 0 || maxY != 0 {
+		t.Errorf("空片段的包围盒应为空矩形, 实际 %v %v %v %v %v", minX, minY, maxX, maxY, err)
+	}
+}
+
+// TestStaticSnapshot 测试生成动画在指定时刻的静态快照
+func TestStaticSnapshot(t *testing.T) {
+	pn := NewPixelNebula()
+	pn.WithStyle(style.GirlStyle)
+	pn.WithTheme(0)
+	pn.WithRotateAnimation("env", 0, 360, 4, -1)
+	pn.WithFadeAnimation("eyes", "1", "0", 2, -1)
+
+	svg, err := pn.Generate("static-id", false).ToStaticSVG(1)
+	if err != nil {
+		t.Fatalf("生成静态快照失败: %v", err)
+	}
+	for _, tag := range []string{"<animate", "<animateTransform", "transform-box"} {
+		if strings.Contains(svg, tag) {
+			t.Errorf("静态快照不应包含 %s", tag)
+		}
+	}
+	if !strings.Contains(svg, `transform="rotate(90 `) {
+		t.Errorf("静态快照应包含1秒时的旋转角度")
+	}
+	if !strings.Contains(svg, `opacity="0.5"`) {
+		t.Errorf("静态快照应包含1秒时的透明度")
+	}
+
+	// ToBase64 仍然输出去掉变换动画的SVG
+	encoded, err := pn.Generate("static-id", false).ToBase64()
+	if err != nil {
+		t.Fatalf("生成Base64失败: %v", err)
+	}
+	decoded, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(encoded, "data:image/svg+xml;base64,"))
+	if strings.Contains(string(decoded), "<animateTransform") {
+		t.Errorf("ToBase64 不应包含 animateTransform")
+	}
+}
+
+// TestCSSAnimationMode 测试CSS关键帧动画输出模式
+func TestCSSAnimationMode(t *testing.T) {
+	generate := func() string {
+		pn := NewPixelNebula()
+		pn.WithStyle(style.GirlStyle)
+		pn.WithTheme(0)
+		pn.WithAnimationMode(animation.ModeCSS)
+		pn.WithRotateAnimation("env", 0, 360, 10, -1)
+		pn.WithBounceAnimation("eyes", "transform", "0,0", "0,-5", 3, 2.5, -1)
+		pn.WithWaveAnimation("clo", 5, 2, "horizontal", 12, -1)
+		pn.WithBlinkAnimation("mouth", 0.3, 1.0, 4, 6, -1)
+		svg, err := pn.Generate("css-id", false).ToSVG()
+		if err != nil {
+			t.Fatalf("生成SVG失败: %v", err)
+		}
+		return svg
+	}
+
+	svg := generate()
+	if strings.Contains(svg, "<animate") {
+		t.Errorf("CSS模式不应包含SMIL动画元素")
+	}
+	for _, want := range []string{"@keyframes pn-rotate-env-0", "#env { animation: pn-rotate-env-0 10s linear infinite;", "#eyes { animation: pn-bounce-eyes-1 2.5s linear infinite forwards; }"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("CSS模式输出缺少 %q", want)
+		}
+	}
+	if svg != generate() {
+		t.Errorf("CSS模式输出应保持字节稳定")
+	}
+
+	static, err := NewPixelNebula().
+		WithStyle(style.GirlStyle).
+		WithAnimationMode(animation.ModeCSS).
+		WithRotateAnimation("env", 0, 360, 10, -1).
+		Generate("css-id", false).
+		ToStaticSVG(0)
+	if err != nil {
+		t.Fatalf("生成静态快照失败: %v", err)
+	}
+	if strings.Contains(static, "@keyframes") {
+		t.Errorf("静态快照不应包含CSS动画")
+	}
+
+	// 渐变动画无法用关键帧实现，以SMIL元素输出
+	gradient, err := NewPixelNebula().
+		WithAnimationMode(animation.ModeCSS).
+		WithGradientAnimation("clo", []string{"#ff0000", "#0000ff"}, 3, -1, true).
+		Generate("css-id", false).
+		ToSVG()
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	if !strings.Contains(gradient, `<animate href="#clo-gradient" attributeName="x1"`) {
+		t.Errorf("CSS模式不应丢弃渐变动画")
+	}
+}
+
+// customAnimation 只能以SMIL输出的自定义动画
+type customAnimation struct{}
+
+func (customAnimation) GenerateSVG() string {
+	return `<set href="#mouth" attributeName="opacity" to="0.5" begin="1s"/>`
+}
+func (customAnimation) GetTargetID() string              { return "mouth" }
+func (customAnimation) GetType() animation.AnimationType { return "custom" }
+
+// cloneableAnimation 实现了 animation.Cloner 的自定义动画
+type cloneableAnimation struct{ to string }
+
+func (a *cloneableAnimation) GenerateSVG() string {
+	return `<set href="#mouth" attributeName="opacity" to="` + a.to + `" begin="1s"/>`
+}
+func (a *cloneableAnimation) GetTargetID() string              { return "mouth" }
+func (a *cloneableAnimation) GetType() animation.AnimationType { return "custom" }
+func (a *cloneableAnimation) Clone() animation.Animation {
+	c := *a
+	return &c
+}
+
+// cancelAfter 在 Err 被调用n次后返回 context.Canceled
+type cancelAfter struct {
+	context.Context
+	n int32
+}
+
+func (c *cancelAfter) Err() error {
+	if atomic.AddInt32(&c.n, -1) < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+// TestReducedMotion 测试响应 prefers-reduced-motion 的动画输出
+func TestReducedMotion(t *testing.T) {
+	rotate := animation.NewRotateAnimation("env", 0, 360, 10, -1)
+	rotate.SetReduced(animation.NewFadeAnimation("env", "1", "0.85", 4, -1))
+
+	pn := NewPixelNebula()
+	pn.WithStyle(style.GirlStyle)
+	pn.WithTheme(0)
+	pn.WithReducedMotion(true)
+	pn.WithAnimation(rotate)
+	pn.WithBounceAnimation("eyes", "transform", "0,0", "0,-5", 3, 2.5, -1)
+	pn.WithGradientAnimation("clo", []string{"#ff0000", "#0000ff"}, 3, -1, true)
+	pn.WithAnimation(customAnimation{})
+
+	svg, err := pn.Generate("reduced-id", false).ToSVG()
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	if strings.Contains(svg, "<animate") || strings.Contains(svg, "<set") {
+		t.Errorf("启用减弱动态效果时不应输出SMIL动画元素")
+	}
+	if !strings.Contains(svg, `<linearGradient id="clo-gradient"`) {
+		t.Errorf("渐变动画应保留静态渐变定义")
+	}
+	media := strings.Index(svg, "@media (prefers-reduced-motion: reduce) {")
+	if media == -1 {
+		t.Fatalf("缺少 prefers-reduced-motion 媒体查询")
+	}
+	reduced := svg[media:]
+	if !strings.Contains(reduced, "#eyes { animation: none; }") {
+		t.Errorf("没有替代动画的弹跳动画应被停用")
+	}
+	if !strings.Contains(reduced, "#env { animation: pn-reduced-fade-env-0 4s linear infinite; }") {
+		t.Errorf("旋转动画应替换为淡入淡出")
+	}
+}
+
+// TestAccessibleSVG 测试无障碍信息输出
+func TestAccessibleSVG(t *testing.T) {
+	pn := NewPixelNebula()
+	pn.WithStyle(style.GirlStyle)
+	pn.WithTheme(2)
+
+	svg, err := pn.Generate("alice", false).SetAccessible(true).ToSVG()
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	for _, want := range []string{
+		`role="img" aria-label="Avatar for alice">`,
+		"<title>Avatar for alice</title>",
+		"<desc>Avatar in the girl style with theme 2</desc>",
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("无障碍输出缺少 %q", want)
+		}
+	}
+
+	svg, err = pn.Generate("bob", true).SetTitle(`Bob & "friends"`).SetDescription("Profile <picture>").ToSVG()
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	for _, want := range []string{
+		`aria-label="Bob &amp; &quot;friends&quot;"`,
+		"<title>Bob &amp; \"friends\"</title>",
+		"<desc>Profile &lt;picture&gt;</desc>",
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("无障碍输出缺少 %q", want)
+		}
+	}
+
+	// 未固定风格时描述列出各部分使用的风格
+	svg, err = NewPixelNebula().Generate("carol", false).SetAccessible(true).ToSVG()
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	if !strings.Contains(svg, "<desc>Avatar ") {
+		t.Errorf("缺少默认描述")
+	}
+}
+
+// TestIDPrefix 测试为元素ID及其引用添加前缀
+func TestIDPrefix(t *testing.T) {
+	pn := NewPixelNebula()
+	pn.WithStyle(style.GirlStyle)
+	pn.WithTheme(0)
+	pn.WithGradientAnimation("env", []string{"#3498db", "#2ecc71"}, 5, -1, true)
+	pn.WithFadeAnimation("eyes", "1", "0.3", 2, -1)
+	pn.WithRotateAnimation("head", 0, 360, 10, -1)
+
+	svg, err := pn.Generate("alice", false).SetIDPrefix("alice-").ToSVG()
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	for _, want := range []string{
+		"id='alice-env'",
+		`id="alice-env-gradient"`,
+		"#alice-env { fill: url(#alice-env-gradient) !important; }",
+		`href="#alice-eyes"`,
+		`href="#alice-env-gradient"`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("前缀输出缺少 %q", want)
+		}
+	}
+	if regexp.MustCompile(`id=['"](env|head|clo|top|eyes|mouth)`).MatchString(svg) {
+		t.Errorf("不应存在未加前缀的ID")
+	}
+
+	// 静态快照按前缀查找动画目标
+	static, err := pn.Generate("alice", false).SetIDPrefix("alice-").ToStaticSVG(2.5)
+	if err != nil {
+		t.Fatalf("生成静态快照失败: %v", err)
+	}
+	if !strings.Contains(static, `transform="rotate(90 `) {
+		t.Errorf("静态快照应包含旋转效果")
+	}
+
+	// CSS模式下关键帧名称同样加上前缀
+	css, err := pn.Generate("bob", false).SetAnimationMode(animation.ModeCSS).SetIDPrefix("bob_").ToSVG()
+	pn.WithAnimationMode(animation.ModeSMIL)
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	if !strings.Contains(css, "@keyframes bob_pn-fade-eyes-1") || !strings.Contains(css, "#bob_eyes { animation: bob_pn-fade-eyes-1 ") {
+		t.Errorf("CSS关键帧名称和选择器应加上前缀")
+	}
+
+	if _, err := pn.Generate("carol", false).SetIDPrefix("1 bad").ToSVG(); err != errors.ErrInvalidIDPrefix {
+		t.Errorf("非法前缀应返回 ErrInvalidIDPrefix, 实际 %v", err)
+	}
+}
+
+// TestSizing 测试输出尺寸、留白和宽高比设置
+func TestSizing(t *testing.T) {
+	pn := NewPixelNebula()
+	pn.WithStyle(style.GirlStyle)
+	pn.WithTheme(0)
+
+	tests := []struct {
+		name   string
+		build  func() *SVGBuilder
+		expect string
+	}{
+		{"default", func() *SVGBuilder { return pn.Generate("size-id", false) },
+			`<svg xmlns="http://www.w3.org/2000/svg" width="231" height="231" viewBox="0 0 231 231">`},
+		{"small", func() *SVGBuilder { return pn.Generate("size-id", false).SetSize(48, 48) },
+			`width="48" height="48" viewBox="0 0 231 231">`},
+		{"wide", func() *SVGBuilder { return pn.Generate("size-id", false).SetSize(512, 256) },
+			`width="512" height="256" viewBox="-115.5 0 462 231">`},
+		{"padding", func() *SVGBuilder { return pn.Generate("size-id", false).SetSize(48, 48).SetPadding(8) },
+			`width="48" height="48" viewBox="-57.75 -57.75 346.5 346.5">`},
+		{"align", func() *SVGBuilder {
+			return pn.Generate("size-id", false).SetSize(512, 256).SetPreserveAspectRatio("xMinYMid meet")
+		}, `width="512" height="256" viewBox="0 0 462 231" preserveAspectRatio="xMinYMid meet">`},
+	}
+	for _, tt := range tests {
+		svg, err := tt.build().ToSVG()
+		if err != nil {
+			t.Fatalf("%s: 生成SVG失败: %v", tt.name, err)
+		}
+		if !strings.Contains(svg, tt.expect) {
+			t.Errorf("%s: 期望包含 %q, 实际 %q", tt.name, tt.expect, svg[:strings.Index(svg, ">")+1])
+		}
+	}
+
+	// 宽图中头像居中，两侧留空
+	img, err := pn.Generate("size-id", false).SetSize(512, 256).ToImage(1)
+	if err != nil {
+		t.Fatalf("生成图像失败: %v", err)
+	}
+	if _, _, _, a := img.At(256, 128).RGBA(); a == 0 {
+		t.Errorf("图像中心不应为透明")
+	}
+	if _, _, _, a := img.At(20, 128).RGBA(); a != 0 {
+		t.Errorf("图像两侧应为透明")
+	}
+
+	if _, err := pn.Generate("size-id", false).SetPreserveAspectRatio("middle").ToSVG(); err != errors.ErrInvalidAspectRatio {
+		t.Errorf("非法的宽高比设置应返回 ErrInvalidAspectRatio, 实际 %v", err)
+	}
+	bad := NewPixelNebula().WithPreserveAspectRatio("middle")
+	if !errors.Is(bad.Err(), errors.ErrInvalidAspectRatio) || bad.AspectRatio != "" {
+		t.Errorf("实例上非法的宽高比设置应记录 ErrInvalidAspectRatio 且不修改设置, 实际 %v", bad.Err())
+	}
+	if _, err := bad.Generate("size-id", false).ToSVG(); !errors.Is(err, errors.ErrInvalidAspectRatio) {
+		t.Errorf("生成时应返回记录的错误, 实际 %v", err)
+	}
+	if _, err := pn.Generate("size-id", false).SetSize(16, 16).SetPadding(8).ToSVG(); err != errors.ErrInvalidSize {
+		t.Errorf("留白过大应返回 ErrInvalidSize, 实际 %v", err)
+	}
+}
+
+// TestMask 测试裁剪形状
+func TestMask(t *testing.T) {
+	pn := NewPixelNebula()
+	pn.WithStyle(style.GuyStyle)
+	pn.WithTheme(0)
+
+	for _, shape := range []MaskShape{MaskCircle, MaskRoundedSquare, MaskSquircle, MaskHexagon} {
+		svg, err := pn.Generate("mask-id", false).SetMask(shape).SetIDPrefix("m-").ToSVG()
+		if err != nil {
+			t.Fatalf("%s: 生成SVG失败: %v", shape, err)
+		}
+		if !strings.Contains(svg, `<clipPath id="m-avatar-mask">`) || !strings.Contains(svg, `<g clip-path="url(#m-avatar-mask)">`) {
+			t.Errorf("%s: 缺少裁剪路径", shape)
+		}
+	}
+
+	// 六边形之外的背景区域应被裁剪掉
+	plain, err := pn.Generate("mask-id", false).ToImage(1)
+	if err != nil {
+		t.Fatalf("生成图像失败: %v", err)
+	}
+	masked, err := pn.Generate("mask-id", false).SetMask(MaskHexagon).ToImage(1)
+	if err != nil {
+		t.Fatalf("生成图像失败: %v", err)
+	}
+	if _, _, _, a := plain.At(40, 30).RGBA(); a == 0 {
+		t.Errorf("未裁剪时背景区域不应为透明")
+	}
+	if _, _, _, a := masked.At(40, 30).RGBA(); a != 0 {
+		t.Errorf("六边形之外的区域应为透明")
+	}
+	if _, _, _, a := masked.At(115, 115).RGBA(); a == 0 {
+		t.Errorf("六边形中心不应为透明")
+	}
+
+	if _, err := pn.Generate("mask-id", false).SetMask("star").ToSVG(); err != errors.ErrInvalidMaskShape {
+		t.Errorf("未知形状应返回 ErrInvalidMaskShape, 实际 %v", err)
+	}
+}
+
+// TestBackground 测试背景模式
+func TestBackground(t *testing.T) {
+	pn := NewPixelNebula()
+
+	for i := 0; i < pn.StyleManager.StyleSetCount(); i++ {
+		pn.Options.StyleIndex = i
+		pn.Options.ThemeIndex = 0
+		for _, mode := range []BackgroundMode{BackgroundLinearGradient, BackgroundRadialGradient, BackgroundPattern} {
+			svg, err := pn.Generate("bg-id", false).SetBackground(mode, "").ToSVG()
+			if err != nil {
+				t.Fatalf("风格 %d %s: 生成SVG失败: %v", i, mode, err)
+			}
+			if !strings.Contains(svg, `id="avatar-bg"`) || !strings.Contains(svg, "fill:url(#avatar-bg)") {
+				t.Errorf("风格 %d %s: 背景未使用渐变或图案", i, mode)
+			}
+		}
+	}
+
+	pn.WithStyle(style.GuyStyle)
+	svg, err := pn.Generate("bg-id", false).SetBackground(BackgroundSolid, "#336699").ToSVG()
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	if !regexp.MustCompile(`id='env'[^>]*fill:#336699;`).MatchString(svg) {
+		t.Errorf("纯色背景未生效")
+	}
+
+	// 透明背景与不含背景的输出一致
+	transparent, err := pn.Generate("bg-id", false).SetBackground(BackgroundTransparent, "").ToSVG()
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	sans, _ := pn.Generate("bg-id", true).ToSVG()
+	if transparent != sans {
+		t.Errorf("透明背景应与不含背景的输出一致")
+	}
+
+	// 图案背景栅格化时使用基础色
+	img, err := pn.Generate("bg-id", false).SetBackground(BackgroundPattern, "#336699").ToImage(1)
+	if err != nil {
+		t.Fatalf("生成图像失败: %v", err)
+	}
+	if r, g, b, _ := img.At(40, 115).RGBA(); r>>8 != 0x33 || g>>8 != 0x66 || b>>8 != 0x99 {
+		t.Errorf("图案背景的栅格颜色错误: %x %x %x", r>>8, g>>8, b>>8)
+	}
+
+	for _, tc := range []struct {
+		mode  BackgroundMode
+		color string
+	}{{BackgroundSolid, ""}, {BackgroundSolid, "blue"}, {"stripes", ""}} {
+		if _, err := pn.Generate("bg-id", false).SetBackground(tc.mode, tc.color).ToSVG(); err != errors.ErrInvalidBackground {
+			t.Errorf("%s %q 应返回 ErrInvalidBackground, 实际 %v", tc.mode, tc.color, err)
+		}
+	}
+}
+
+// TestNewWithOptions 测试使用选项创建实例
+func TestNewWithOptions(t *testing.T) {
+	pn, err := New(
+		WithStyle(style.GirlStyle),
+		WithTheme(1),
+		WithSize(128, 128),
+		WithPadding(8),
+		WithDefaultCache(),
+		WithAnimations(animation.NewRotateAnimation("env", 0, 360, 10, -1)),
+		WithAnimationMode(animation.ModeCSS),
+	)
+	if err != nil {
+		t.Fatalf("创建实例失败: %v", err)
+	}
+	if pn.Options.ThemeIndex != 1 || pn.Width != 128 || pn.Padding != 8 || pn.Cache == nil {
+		t.Errorf("选项未生效: %+v", pn.Options)
+	}
+	if _, err := pn.Generate("options-id", false).ToSVG(); err != nil {
+		t.Errorf("生成SVG失败: %v", err)
+	}
+
+	// 所有无效选项都应在同一个错误中报告，且不会 panic
+	badCache := cache.DefaultCacheOptions
+	badCache.EvictionType = "random"
+	_, err = New(
+		WithStyle("unknown"),
+		WithTheme(-1),
+		WithSize(0, 100),
+		WithCache(badCache),
+		WithAnimations(animation.NewFadeAnimation("", "1", "0", 0, 1)),
+		WithConcurrencyPool(0),
+	)
+	if err == nil {
+		t.Fatal("无效选项应返回错误")
+	}
+	for _, target := range []error{
+		errors.ErrInvalidStyleName,
+		errors.ErrInvalidTheme,
+		errors.ErrInvalidSize,
+		errors.ErrInvalidCacheOptions,
+		errors.ErrInvalidAnimation,
+		errors.ErrInvalidOption,
+	} {
+		if !errors.Is(err, target) {
+			t.Errorf("错误中缺少 %v: %v", target, err)
+		}
+	}
+
+	// 主题索引超出所选风格的范围
+	if _, err := New(WithStyle(style.GirlStyle), WithTheme(100)); !errors.Is(err, errors.ErrInvalidTheme) {
+		t.Errorf("超出范围的主题应返回 ErrInvalidTheme, 实际 %v", err)
+	}
+
+	// 链式方法遇到无效的风格或主题时记录错误而不是 panic
+	chain := NewPixelNebula().WithStyle("unknown")
+	if !errors.Is(chain.Err(), errors.ErrInvalidStyleName) || chain.Options.StyleIndex != -1 {
+		t.Errorf("未知风格应记录 ErrInvalidStyleName 且不修改设置, 实际 %v", chain.Err())
+	}
+	if _, err := chain.Generate("options-id", false).ToSVG(); !errors.Is(err, errors.ErrInvalidStyleName) {
+		t.Errorf("生成时应返回记录的错误, 实际 %v", err)
+	}
+	chain = NewPixelNebula().WithStyle(style.GirlStyle).WithTheme(100)
+	var indexErr *errors.IndexError
+	if !errors.As(chain.Err(), &indexErr) || indexErr.Got != 100 || chain.Options.ThemeIndex != -1 {
+		t.Errorf("越界主题应记录 *IndexError 且不修改设置, 实际 %v", chain.Err())
+	}
+	if _, err := chain.Generate("options-id", false).ToSVG(); !errors.Is(err, errors.ErrInvalidTheme) {
+		t.Errorf("生成时应返回记录的错误, 实际 %v", err)
+	}
+	if chain.WithTheme(0).Err() == nil {
+		t.Errorf("之后的有效设置不应清除记录的错误")
+	}
+}
+
+// TestTypedErrors 测试携带索引范围的错误类型
+func TestTypedErrors(t *testing.T) {
+	pn := NewPixelNebula()
+	pn.WithStyle(style.GirlStyle)
+
+	_, err := pn.Generate("typed-id", false).SetTheme(99).ToSVG()
+	var indexErr *errors.IndexError
+	if !errors.As(err, &indexErr) {
+		t.Fatalf("越界主题应返回 *IndexError, 实际 %T", err)
+	}
+	girl, _ := pn.StyleManager.GetStyleIndex(style.GirlStyle)
+	want := errors.IndexError{Kind: errors.IndexTheme, Got: 99, Min: 0, Max: pn.ThemeManager.ThemeCount(girl) - 1, Style: girl}
+	if *indexErr != want {
+		t.Errorf("IndexError 内容错误: %+v", *indexErr)
+	}
+	if !errors.Is(err, errors.ErrInvalidTheme) || errors.Is(err, errors.ErrInvalidPart) {
+		t.Errorf("IndexError 应只匹配 ErrInvalidTheme")
+	}
+
+	_, err = pn.Generate("typed-id", false).SetStyleByIndex(-1).ToSVG()
+	if !errors.As(err, &indexErr) || indexErr.Kind != errors.IndexStyle || !errors.Is(err, errors.ErrInvalidStyleName) {
+		t.Errorf("越界风格索引应返回风格的 IndexError, 实际 %v", err)
+	}
+
+	if _, err := pn.ThemeManager.GetTheme(0, 100); !errors.Is(err, errors.ErrInvalidPart) {
+		t.Errorf("越界部分索引应匹配 ErrInvalidPart, 实际 %v", err)
+	}
+
+	_, err = pn.StyleManager.GetShape(0, "hat")
+	var shapeErr *errors.ShapeError
+	if !errors.As(err, &shapeErr) || shapeErr.Style != 0 || shapeErr.Part != "hat" || !errors.Is(err, errors.ErrInvalidShapeType) {
+		t.Errorf("缺少形状应返回 *ShapeError, 实际 %v", err)
+	}
+	if _, err := pn.StyleManager.GetShape(1000, style.TypeEyes); !errors.Is(err, errors.ErrInvalidShapeSetIndex) {
+		t.Errorf("越界形状集合应匹配 ErrInvalidShapeSetIndex, 实际 %v", err)
+	}
+}
+
+// TestLogger 测试结构化日志
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	pn := NewPixelNebula().WithLogger(logger)
+	pn.ClearCache()
+	if !strings.Contains(buf.String(), `"msg":"cache not initialized","method":"ClearCache"`) {
+		t.Errorf("未初始化缓存时应记录日志: %s", buf.String())
+	}
+
+	buf.Reset()
+	pn.WithCache(cache.CacheOptions{Enabled: true, Size: 10, EvictionType: "lru"})
+	pn.DeleteCacheItem("bad-key")
+	pn.ClearCache()
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"invalid cache key","key":"bad-key"`) {
+		t.Errorf("无效的缓存键应记录日志: %s", out)
+	}
+	if !strings.Contains(out, `"msg":"cache cleared"`) {
+		t.Errorf("缓存应使用实例的日志记录器: %s", out)
+	}
+
+	// 未设置日志记录器时不输出日志
+	if NewPixelNebula().logger().Enabled(context.Background(), slog.LevelError) {
+		t.Errorf("默认日志记录器应不输出日志")
+	}
+}
+
+// TestNamespace 测试带命名空间的哈希
+func TestNamespace(t *testing.T) {
+	ids := []string{"alice@example.com", "bob@example.com", "carol@example.com", "dave@example.com"}
+	render := func(pn *PixelNebula) []string {
+		out := make([]string, len(ids))
+		for i, id := range ids {
+			svg, err := pn.Generate(id, false).ToSVG()
+			if err != nil {
+				t.Fatalf("生成SVG失败: %v", err)
+			}
+			out[i] = svg
+		}
+		return out
+	}
+
+	plain := render(NewPixelNebula())
+	tenantA := render(NewPixelNebula().WithNamespace("tenant-a"))
+	tenantB := render(NewPixelNebula().WithNamespace("tenant-b"))
+	if again := render(NewPixelNebula().WithNamespace("tenant-a")); strings.Join(again, "") != strings.Join(tenantA, "") {
+		t.Errorf("同一命名空间的输出应保持稳定")
+	}
+	if def := render(NewPixelNebula().WithHashFunc(nil)); strings.Join(def, "") != strings.Join(plain, "") {
+		t.Errorf("默认哈希函数应为 sha256")
+	}
+
+	for i, id := range ids {
+		if tenantA[i] == tenantB[i] || tenantA[i] == plain[i] {
+			t.Errorf("%s 在不同命名空间下应生成不同的头像", id)
+		}
+		if again := render(NewPixelNebula().WithNamespace("tenant-b")); again[i] != tenantB[i] {
+			t.Errorf("%s 在同一命名空间下的输出应保持稳定", id)
+		}
+	}
+
+	// 并行批量生成的每个worker也应使用命名空间
+	pn := NewPixelNebula().WithNamespace("tenant-a").WithParallelRender(true).WithConcurrencyPool(2)
+	batch, err := pn.GenerateBatch(ids, false, nil)
+	if err != nil {
+		t.Fatalf("批量生成失败: %v", err)
+	}
+	for i, id := range ids {
+		if batch[id] != tenantA[i] {
+			t.Errorf("批量生成的 %s 与单独生成的结果不一致", id)
+		}
+	}
+
+	// 默认的无障碍标题不应包含ID，只更换哈希函数时仍包含ID
+	titled, _ := NewPixelNebula().WithNamespace("tenant-a").Generate(ids[0], false).SetAccessible(true).ToSVG()
+	if strings.Contains(titled, ids[0]) {
+		t.Errorf("设置命名空间后输出不应包含ID")
+	}
+	titled, _ = NewPixelNebula().WithHashFunc(sha512.New).Generate(ids[0], false).SetAccessible(true).ToSVG()
+	if !strings.Contains(titled, "<title>Avatar for "+ids[0]+"</title>") {
+		t.Errorf("只更换哈希函数时默认标题应包含ID")
+	}
+	if pn, err := New(WithNamespace("tenant-a")); err != nil || !pn.Namespaced {
+		t.Errorf("New 使用命名空间时应设置 Namespaced, 错误 %v", err)
+	}
+
+	if _, err := New(WithNamespace("")); !errors.Is(err, errors.ErrInvalidOption) {
+		t.Errorf("空命名空间应返回 ErrInvalidOption, 实际 %v", err)
+	}
+}
+
+// TestSelectionV2 测试基于会合哈希的选择算法
+func TestSelectionV2(t *testing.T) {
+	pn := NewPixelNebula().WithSelectionVersion(SelectionV2)
+
+	const n = 2000
+	before := make([]map[style.ShapeType][2]int, n)
+	for i := range before {
+		keys, err := pn.partKeys(fmt.Sprintf("user-%d", i), pn.Options)
+		if err != nil {
+			t.Fatalf("计算部分失败: %v", err)
+		}
+		before[i] = keys
+	}
+
+	// 添加一个新风格，只有少量部分应改用新风格，其余保持不变
+	shapes := style.StyleSet{}
+	for _, part := range partOrder {
+		shape, _ := pn.StyleManager.GetShape(0, part)
+		shapes[part] = shape
+	}
+	var themes theme.Theme
+	for j := 0; j < pn.ThemeManager.ThemeCount(0); j++ {
+		part, _ := pn.ThemeManager.GetTheme(0, j)
+		themes = append(themes, part)
+	}
+	added := pn.StyleManager.AddNamedStyleSet("extra", shapes)
+	if pn.ThemeManager.AddTheme(themes) != added {
+		t.Fatalf("新风格和主题的索引不一致")
+	}
+
+	moved, total := 0, 0
+	for i := range before {
+		keys, _ := pn.partKeys(fmt.Sprintf("user-%d", i), pn.Options)
+		for part, key := range keys {
+			total++
+			if key == before[i][part] {
+				continue
+			}
+			moved++
+			if key[0] != added {
+				t.Fatalf("部分只应移动到新风格, %v -> %v", before[i][part], key)
+			}
+		}
+	}
+	// 期望约 1/风格数 的部分移动
+	if share := float64(moved) / float64(total); share == 0 || share > 2.0/float64(added+1) {
+		t.Errorf("移动比例异常: %.4f", share)
+	}
+
+	// 在最前面插入一个主题，主题按颜色标识，已有的选择应基本保持不变
+	pn = NewPixelNebula().WithSelectionVersion(SelectionV2)
+	colorsOf := func(keys map[style.ShapeType][2]int) map[style.ShapeType]string {
+		colors := make(map[style.ShapeType]string, len(keys))
+		for part, key := range keys {
+			tp, _ := pn.ThemeManager.GetTheme(key[0], key[1])
+			colors[part] = fmt.Sprint(key[0], tp[string(part)])
+		}
+		return colors
+	}
+	chosen := make([]map[style.ShapeType]string, n)
+	for i := range chosen {
+		keys, _ := pn.partKeys(fmt.Sprintf("user-%d", i), pn.Options)
+		chosen[i] = colorsOf(keys)
+	}
+	all := make([]theme.Theme, pn.ThemeManager.StyleCount())
+	for i := range all {
+		for j := 0; j < pn.ThemeManager.ThemeCount(i); j++ {
+			tp, _ := pn.ThemeManager.GetTheme(i, j)
+			all[i] = append(all[i], tp)
+		}
+	}
+	inserted := theme.ThemePart{}
+	for part, colors := range all[0][0] {
+		scheme := make(theme.ColorScheme, len(colors))
+		for k := range scheme {
+			scheme[k] = fmt.Sprintf("#%06x", k+1)
+		}
+		inserted[part] = scheme
+	}
+	all[0] = append(theme.Theme{inserted}, all[0]...)
+	pn.ThemeManager.CustomizeTheme(all)
+
+	changed, total := 0, 0
+	for i := range chosen {
+		keys, _ := pn.partKeys(fmt.Sprintf("user-%d", i), pn.Options)
+		for part, colors := range colorsOf(keys) {
+			total++
+			if colors != chosen[i][part] {
+				changed++
+				if keys[part] != [2]int{0, 0} {
+					t.Fatalf("部分只应改用新插入的主题, 实际 %v", keys[part])
+				}
+			}
+		}
+	}
+	if share := float64(changed) / float64(total); share > 0.1 {
+		t.Errorf("插入主题后变化比例过高: %.4f", share)
+	}
+
+	// 链式方法遇到无效版本时记录错误而不是 panic
+	bad := NewPixelNebula().WithSelectionVersion(99)
+	if !errors.Is(bad.Err(), errors.ErrInvalidOption) || bad.Selection != NewPixelNebula().Selection {
+		t.Errorf("无效版本应记录 ErrInvalidOption 且不修改版本, 实际 %v", bad.Err())
+	}
+	if _, err := bad.Generate("stable-id", false).ToSVG(); !errors.Is(err, errors.ErrInvalidOption) {
+		t.Errorf("生成时应返回记录的错误, 实际 %v", err)
+	}
+
+	// 默认版本的输出保持不变
+	v1, _ := NewPixelNebula().Generate("stable-id", false).ToSVG()
+	explicit, _ := NewPixelNebula().WithSelectionVersion(SelectionV1).Generate("stable-id", false).ToSVG()
+	if v1 != explicit {
+		t.Errorf("SelectionV1 应与默认行为一致")
+	}
+
+	if _, err := New(WithSelectionVersion(99)); !errors.Is(err, errors.ErrInvalidOption) {
+		t.Errorf("未知版本应返回 ErrInvalidOption, 实际 %v", err)
+	}
+}
+
+// TestSelectionV3Distribution 测试独立比特选择的分布
+func TestSelectionV3Distribution(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过耗时的分布测试")
+	}
+	pn := NewPixelNebula().WithSelectionVersion(SelectionV3)
+	styleCount := pn.StyleManager.StyleSetCount()
+
+	const n = 1000000
+	styles := make(map[style.ShapeType][]int, len(partOrder))
+	themes := make(map[style.ShapeType]map[[2]int]int, len(partOrder))
+	for _, part := range partOrder {
+		styles[part] = make([]int, styleCount)
+		themes[part] = make(map[[2]int]int)
+	}
+	for i := 0; i < n; i++ {
+		keys, err := pn.partKeys("id-"+strconv.Itoa(i), pn.Options)
+		if err != nil {
+			t.Fatalf("计算部分失败: %v", err)
+		}
+		for part, key := range keys {
+			styles[part][key[0]]++
+			themes[part][key]++
+		}
+	}
+
+	// 卡方检验，阈值取自由度加6倍标准差
+	limit := func(df int) float64 { return float64(df) + 6*math.Sqrt(2*float64(df)) }
+	for _, part := range partOrder {
+		chi := 0.0
+		expected := float64(n) / float64(styleCount)
+		for _, c := range styles[part] {
+			chi += (float64(c) - expected) * (float64(c) - expected) / expected
+		}
+		if chi > limit(styleCount-1) {
+			t.Errorf("%s: 风格分布不均匀, 卡方值 %.1f", part, chi)
+		}
+
+		// 给定风格时主题应均匀分布
+		chi, df := 0.0, 0
+		for s := 0; s < styleCount; s++ {
+			count := pn.ThemeManager.ThemeCount(s)
+			expected := float64(styles[part][s]) / float64(count)
+			for th := 0; th < count; th++ {
+				c := float64(themes[part][[2]int{s, th}])
+				chi += (c - expected) * (c - expected) / expected
+			}
+			df += count - 1
+		}
+		if chi > limit(df) {
+			t.Errorf("%s: 主题分布不均匀, 卡方值 %.1f (自由度 %d)", part, chi, df)
+		}
+	}
+}
+
+// TestPartStyles 测试组合不同风格的部分
+func TestPartStyles(t *testing.T) {
+	pn := NewPixelNebula().
+		WithStyle(style.GirlStyle).
+		WithTheme(0).
+		WithPartStyles(style.TypeEyes, style.RoboStyle, style.MechStyle, style.NeonStyle)
+
+	girl, _ := pn.StyleManager.GetStyleIndex(style.GirlStyle)
+	allowed := map[int]bool{}
+	for _, s := range []style.StyleType{style.RoboStyle, style.MechStyle, style.NeonStyle} {
+		index, _ := pn.StyleManager.GetStyleIndex(s)
+		allowed[index] = true
+	}
+
+	seen := map[int]bool{}
+	for i := 0; i < 200; i++ {
+		keys, err := pn.partKeys(fmt.Sprintf("mix-%d", i), pn.Options)
+		if err != nil {
+			t.Fatalf("计算部分失败: %v", err)
+		}
+		for part, key := range keys {
+			if part == style.TypeEyes {
+				if !allowed[key[0]] {
+					t.Fatalf("眼睛使用了不允许的风格 %d", key[0])
+				}
+				seen[key[0]] = true
+			} else if key != [2]int{girl, 0} {
+				t.Fatalf("%s 应保持固定的风格和主题, 实际 %v", part, key)
+			}
+		}
+	}
+	if len(seen) != len(allowed) {
+		t.Errorf("眼睛应分布在所有允许的风格中, 实际 %v", seen)
+	}
+
+	if _, err := pn.Generate("mix-0", false).ToSVG(); err != nil {
+		t.Errorf("生成SVG失败: %v", err)
+	}
+
+	// 取消限制后恢复固定风格
+	pn.WithPartStyles(style.TypeEyes)
+	if keys, _ := pn.partKeys("mix-0", pn.Options); keys[style.TypeEyes] != [2]int{girl, 0} {
+		t.Errorf("取消限制后眼睛应使用固定风格")
+	}
+
+	// 链式方法遇到无效的风格时记录错误而不是 panic
+	bad := NewPixelNebula().WithPartStyles(style.TypeEyes, "unknown")
+	if !errors.Is(bad.Err(), errors.ErrInvalidStyleName) || len(bad.PartStyles) != 0 {
+		t.Errorf("无效的允许列表应记录错误且不修改限制, 实际 %v", bad.Err())
+	}
+	if _, err := bad.GenerateBatch([]string{"mix-0"}, false, nil); !errors.Is(err, errors.ErrInvalidStyleName) {
+		t.Errorf("批量生成时应返回记录的错误, 实际 %v", err)
+	}
+
+	_, err := New(WithPartStyles(style.TypeEyes, "unknown"), WithPartStyles("hat", style.RoboStyle))
+	if !errors.Is(err, errors.ErrInvalidStyleName) || !errors.Is(err, errors.ErrInvalidShapeType) {
+		t.Errorf("无效的允许列表应返回错误, 实际 %v", err)
+	}
+}
+
+// TestPartOverrides 测试替换单个部分
+func TestPartOverrides(t *testing.T) {
+	pn := NewPixelNebula().WithDefaultCache()
+	id := "override-id"
+
+	base, err := pn.partKeys(id, pn.Options)
+	if err != nil {
+		t.Fatalf("计算部分失败: %v", err)
+	}
+	robo, _ := pn.StyleManager.GetStyleIndex(style.RoboStyle)
+
+	sb := pn.Generate(id, false).SetPart(style.TypeEyes, robo, 1)
+	keys, _ := pn.partKeys(id, sb.options())
+	for part, key := range keys {
+		if part == style.TypeEyes {
+			if key != [2]int{robo, 1} {
+				t.Errorf("眼睛应使用固定的风格和主题, 实际 %v", key)
+			}
+		} else if key != base[part] {
+			t.Errorf("%s 应保持按ID选择的结果", part)
+		}
+	}
+
+	eyes, _ := pn.StyleManager.GetShape(robo, style.TypeEyes)
+	svg, err := sb.SetPartColors(style.TypeTop, theme.ColorScheme{"#123456"}).ToSVG()
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	eyesPath := regexp.MustCompile(`d="[^"]*"`).FindString(eyes)
+	if !strings.Contains(svg, eyesPath) {
+		t.Errorf("输出中缺少固定风格的眼睛")
+	}
+	if !regexp.MustCompile(`<[a-z]+ id='top'[^>]*fill:#123456;`).MatchString(svg) {
+		t.Errorf("头顶颜色未被替换")
+	}
+
+	// 替换部分的结果不应影响缓存中的默认头像
+	plain, _ := pn.Generate(id, false).ToSVG()
+	if plain == svg || strings.Contains(plain, "#123456") {
+		t.Errorf("默认头像不应受替换影响")
+	}
+
+	if _, err := pn.Generate(id, false).SetPart(style.TypeEyes, 1000, 0).ToSVG(); !errors.Is(err, errors.ErrInvalidStyleName) {
+		t.Errorf("越界风格应返回 IndexError, 实际 %v", err)
+	}
+	if _, err := pn.Generate(id, false).SetPartColors(style.TypeTop, theme.ColorScheme{"red"}).ToSVG(); !errors.Is(err, errors.ErrInvalidColor) {
+		t.Errorf("无效颜色应返回 ErrInvalidColor, 实际 %v", err)
+	}
+}
+
+func TestAvatarSpec(t *testing.T) {
+	pn := NewPixelNebula()
+	rotate := animation.NewRotateAnimation("clo", 0, 360, 4, -1)
+	rotate.SetReduced(animation.NewFadeAnimation("clo", "1", "0.8", 2, -1))
+	pn.WithAnimation(rotate).WithReducedMotion(true)
+	pn.WithBlinkAnimation("eyes", 0.2, 1, 2, 3, -1)
+
+	id := "spec-id"
+	sb := pn.Generate(id, false).
+		SetSize(128, 128).
+		SetBackground(BackgroundLinearGradient, "").
+		SetMask(MaskCircle).
+		SetPartColors(style.TypeTop, theme.ColorScheme{"#123456"})
+	want, err := sb.ToSVG()
+	if err != nil {
+		t.Fatalf("生成SVG失败: %v", err)
+	}
+	spec, err := sb.Describe()
+	if err != nil {
+		t.Fatalf("生成描述失败: %v", err)
+	}
+	if len(spec.Parts) != len(partOrder) || len(spec.Animations) != 2 {
+		t.Fatalf("描述不完整: %d 个部分, %d 个动画", len(spec.Parts), len(spec.Animations))
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	if strings.Contains(string(data), id) {
+		t.Errorf("描述中不应包含头像ID")
+	}
+	var decoded AvatarSpec
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("反序列化失败: %v", err)
+	}
+
+	// 更换哈希算法、选择算法并增加风格后，描述仍应生成相同的SVG
+	other := NewPixelNebula().WithNamespace("other").WithSelectionVersion(SelectionV2)
+	other.StyleManager.AddNamedStyleSet("extra", style.StyleSet{
+		style.TypeEnv: `<path id='env' d="M0 0h231v231H0z" style="fill:#000;"/>`,
+	})
+	other.ThemeManager.AddTheme(theme.Theme{{"env": {"000"}}})
+	got, err := other.RenderSpec(&decoded)
+	if err != nil {
+		t.Fatalf("按描述生成失败: %v", err)
+	}
+	if got != want {
+		t.Errorf("按描述生成的SVG与原SVG不同")
+	}
+	if len(other.AnimManager.GetAnimations()) != 0 {
+		t.Errorf("描述中的动画不应添加到实例")
+	}
+
+	// 风格的形状被修改后应返回错误，而不是生成不同的头像
+	changed := NewPixelNebula()
+	sets := make([]style.StyleSet, changed.StyleManager.StyleSetCount())
+	for i := range sets {
+		sets[i] = style.StyleSet{}
+		for _, part := range partOrder {
+			if shape, err := changed.StyleManager.GetShape(i, part); err == nil {
+				sets[i][part] = shape
+			}
+		}
+	}
+	last := decoded.Parts[len(decoded.Parts)-1]
+	sets[last.StyleIndex][last.Part] = `<path d="M0 0h10v10H0z" style="fill:#000;"/>`
+	changed.StyleManager.CustomizeStyle(sets)
+	if _, err := changed.RenderSpec(&decoded); !errors.Is(err, errors.ErrInvalidSpec) {
+		t.Errorf("形状被修改时应返回 ErrInvalidSpec, 实际 %v", err)
+	}
+	legacy := decoded
+	legacy.Parts = append([]PartSpec(nil), decoded.Parts...)
+	for i := range legacy.Parts {
+		legacy.Parts[i].Shape = ""
+	}
+	if _, err := changed.RenderSpec(&legacy); err != nil {
+		t.Errorf("没有形状摘要的描述不应校验形状, 实际 %v", err)
+	}
+
+	decoded.Parts[0].Style = "missing"
+	if _, err := other.RenderSpec(&decoded); !errors.Is(err, errors.ErrInvalidStyleName) {
+		t.Errorf("风格不存在时应返回 ErrInvalidStyleName, 实际 %v", err)
+	}
+	if _, err := other.RenderSpec(&AvatarSpec{Version: SpecVersion}); !errors.Is(err, errors.ErrInvalidSpec) {
+		t.Errorf("缺少部分时应返回 ErrInvalidSpec, 实际 %v", err)
+	}
+}
+
+func TestParseSVG(t *testing.T) {
+	pn := NewPixelNebula().
+		WithRotateAnimation("env", 0, 360, 4, -1).
+		WithWaveAnimation("clo", 5, 2.5, "horizontal", 2, -1).
+		WithBlinkAnimation("eyes", 0.2, 1, 3, 2, 2)
+
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("parse-%d", i)
+		sb := pn.Generate(id, i%2 == 1).SetSize(120, 80).SetPadding(6).SetMask(MaskCircle)
+		svg, err := sb.ToSVG()
+		if err != nil {
+			t.Fatalf("生成SVG失败: %v", err)
+		}
+		want, _ := sb.Describe()
+
+		spec, err := pn.ParseSVG(svg)
+		if err != nil {
+			t.Fatalf("解析SVG失败: %v", err)
+		}
+		if spec.Width != 120 || spec.Height != 80 || spec.Padding != 6 || spec.Mask != MaskCircle || spec.SansEnv != (i%2 == 1) {
+			t.Errorf("%s: 尺寸、裁剪或背景还原错误: %+v", id, spec)
+		}
+		// 不同风格的形状或不同主题用到的颜色可能相同，只比较形状，颜色由生成结果比较
+		for _, p := range spec.Parts {
+			for _, w := range want.Parts {
+				if w.Part != p.Part {
+					continue
+				}
+				got, _ := pn.StyleManager.GetShape(p.StyleIndex, p.Part)
+				exp, _ := pn.StyleManager.GetShape(w.StyleIndex, w.Part)
+				if got != exp || p.Theme < 0 {
+					t.Errorf("%s: %s 应为风格 %d 主题 %d, 实际 %d %d", id, p.Part, w.StyleIndex, w.Theme, p.StyleIndex, p.Theme)
+				}
+			}
+		}
+		// 不含背景形状时，背景上的旋转动画不会输出
+		if n := 3 - i%2; len(spec.Animations) != n {
+			t.Errorf("%s: 应还原%d个动画, 实际 %d", id, n, len(spec.Animations))
+		}
+
+		got, err := pn.RenderSpec(spec)
+		if err != nil {
+			t.Fatalf("按解析结果生成失败: %v", err)
+		}
+		if got != svg {
+			t.Errorf("%s: 按解析结果生成的SVG与原SVG不同", id)
+		}
+	}
+
+	// 无障碍信息经过解析和重新生成后保持不变
+	for _, sb := range []*SVGBuilder{
+		pn.Generate("parse-a11y", false).SetAccessible(true),
+		pn.Generate("parse-a11y", false).SetTitle(`Tom & "Jerry" <3>`).SetDescription("a < b & c").SetIDPrefix("p-"),
+	} {
+		svg, err := sb.ToSVG()
+		if err != nil {
+			t.Fatalf("生成SVG失败: %v", err)
+		}
+		want, _ := sb.Describe()
+		spec, err := pn.ParseSVG(svg)
+		if err != nil {
+			t.Fatalf("解析无障碍SVG失败: %v", err)
+		}
+		if !spec.Accessible || spec.Title != want.Title || spec.Description != want.Description || want.Title == "" {
+			t.Errorf("无障碍信息还原错误: %q %q, 期望 %q %q", spec.Title, spec.Description, want.Title, want.Description)
+		}
+		if got, err := pn.RenderSpec(spec); err != nil || got != svg {
+			t.Errorf("按解析结果生成的无障碍SVG与原SVG不同: %v", err)
+		}
+		if got, err := pn.RenderSpec(want); err != nil || got != svg {
+			t.Errorf("按描述生成的无障碍SVG与原SVG不同: %v", err)
+		}
+	}
+
+	if _, err := pn.ParseSVG("<svg></svg>"); !errors.Is(err, errors.ErrInvalidSVG) {
+		t.Errorf("无法识别的SVG应返回 ErrInvalidSVG, 实际 %v", err)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	pn := NewPixelNebula().WithSelectionVersion(SelectionV2).WithRotateAnimation("env", 0, 360, 4, -1)
+	id := "metadata-id"
+
+	for _, format := range []MetadataFormat{MetadataJSON, MetadataRDF} {
+		sb := pn.Generate(id, false).SetSize(100, 100).SetAccessible(true).SetMetadata(format)
+		svg, err := sb.ToSVG()
+		if err != nil {
+			t.Fatalf("生成SVG失败: %v", err)
+		}
+		if strings.Index(svg, "<metadata") < strings.Index(svg, "</desc>") {
+			t.Errorf("%s: 元数据应位于 title 和 desc 之后", format)
+		}
+
+		m, err := ReadMetadata(svg)
+		if err != nil {
+			t.Fatalf("%s: 读取元数据失败: %v", format, err)
+		}
+		if m.Format != format || !m.Verified || m.Generator != modulePath || m.Version == "" {
+			t.Errorf("%s: 元数据不正确: %+v", format, m)
+		}
+		if m.Selection != SelectionV2 || m.Width != 100 || m.Height != 100 || len(m.Parts) != len(partOrder) {
+			t.Errorf("%s: 选择算法或尺寸不正确: %+v", format, m)
+		}
+		if len(m.Animations) != 1 || m.Animations[0] != (AnimationMetadata{Type: animation.Rotate, Target: "env"}) {
+			t.Errorf("%s: 动画列表不正确: %+v", format, m.Animations)
+		}
+		spec, _ := sb.Describe()
+		for i, p := range m.Parts {
+			if w := spec.Parts[i]; p.Part != w.Part || p.Style != w.Style || p.StyleIndex != w.StyleIndex || p.Theme != w.Theme {
+				t.Errorf("%s: %s 的风格或主题不正确: %+v", format, p.Part, p)
+			}
+		}
+		if strings.Contains(svg[strings.Index(svg, "<metadata"):strings.Index(svg, "</metadata>")], id) {
+			t.Errorf("%s: 元数据中不应包含头像ID", format)
+		}
+
+		// 修改内容后摘要不再一致
+		tampered, err := ReadMetadata(strings.Replace(svg, "<path", "<path data-x=\"1\"", 1))
+		if err != nil || tampered.Verified {
+			t.Errorf("%s: 修改后的SVG不应通过校验", format)
+		}
+
+		parsed, err := pn.ParseSVG(svg)
+		if err != nil {
+			t.Fatalf("%s: 解析带元数据的SVG失败: %v", format, err)
+		}
+		if parsed.Metadata != format || parsed.Selection != SelectionV2 {
+			t.Errorf("%s: 解析结果应包含元数据格式和选择算法版本", format)
+		}
+	}
+
+	// 批量生成与单个生成经过相同的处理，串行和并行都嵌入元数据
+	pn.WithMetadata(MetadataJSON)
+	want, _ := pn.Generate(id, false).ToSVG()
+	for _, parallel := range []bool{false, true} {
+		opts := *pn.Options
+		opts.ParallelRender, opts.ConcurrencyPool = parallel, 2
+		batch, err := pn.GenerateBatch([]string{id, "other-id"}, false, &opts)
+		if err != nil {
+			t.Fatalf("批量生成失败: %v", err)
+		}
+		if batch[id] != want {
+			t.Errorf("并行 %v: 批量生成的SVG应与单个生成一致", parallel)
+		}
+	}
+	pn.WithMetadata(MetadataNone)
+
+	bad := NewPixelNebula().WithMetadata("yaml")
+	if !errors.Is(bad.Err(), errors.ErrInvalidOption) || bad.Metadata != MetadataNone {
+		t.Errorf("实例上的未知格式应记录 ErrInvalidOption 且不修改设置, 实际 %v", bad.Err())
+	}
+
+	plain, _ := pn.Generate(id, false).ToSVG()
+	if _, err := ReadMetadata(plain); !errors.Is(err, errors.ErrInvalidSVG) {
+		t.Errorf("没有元数据时应返回 ErrInvalidSVG, 实际 %v", err)
+	}
+	if _, err := pn.Generate(id, false).SetMetadata("yaml").ToSVG(); !errors.Is(err, errors.ErrInvalidOption) {
+		t.Errorf("未知格式应返回 ErrInvalidOption, 实际 %v", err)
+	}
+}
+
+// TestRendererConcurrent 应使用 go test -race 运行
+func TestRendererConcurrent(t *testing.T) {
+	rotate := animation.NewRotateAnimation("env", 0, 360, 4, -1)
+	pn := NewPixelNebula().WithSelectionVersion(SelectionV2).WithAnimation(rotate)
+	r := pn.Renderer()
+
+	opts := []*RenderOptions{
+		nil,
+		{Width: 64, Height: 64, Mask: MaskCircle},
+		{Background: BackgroundRadialGradient, IDPrefix: "a-", Metadata: MetadataJSON},
+		{SansEnv: true, Accessible: true, PartColors: map[style.ShapeType]theme.ColorScheme{style.TypeTop: {"#123456"}}},
+	}
+	ids := make([]string, 32)
+	want := make(map[string]string)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("renderer-%d", i)
+		for j, o := range opts {
+			svg, err := r.Render(context.Background(), ids[i], o)
+			if err != nil {
+				t.Fatalf("渲染失败: %v", err)
+			}
+			want[fmt.Sprint(i, "/", j)] = svg
+		}
+	}
+
+	if plain, _ := pn.Generate(ids[0], false).ToSVG(); plain != want["0/0"] {
+		t.Errorf("渲染器的结果应与原实例相同")
+	}
+
+	// 创建渲染器后修改原实例不应影响渲染结果
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pn.WithTheme(0).WithBlinkAnimation("eyes", 0.2, 1, 2, 2, -1).WithSize(32, 32)
+		pn.ThemeManager.CustomizeTheme(nil)
+		rotate.Duration = 1
+	}()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for n := 0; n < 50; n++ {
+				i, j := (g*7+n)%len(ids), (g+n)%len(opts)
+				svg, err := r.Render(context.Background(), ids[i], opts[j])
+				if err != nil {
+					t.Errorf("并发渲染失败: %v", err)
+					return
+				}
+				if svg != want[fmt.Sprint(i, "/", j)] {
+					t.Errorf("%s 的并发渲染结果与串行结果不同", ids[i])
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	<-done
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Render(ctx, "renderer-0", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("取消的 context 应返回 context.Canceled, 实际 %v", err)
+	}
+	// 开始渲染后才取消，并行渲染各部分时也应停止
+	for _, parallel := range []bool{false, true} {
+		pr := NewPixelNebula().WithParallelRender(parallel).Renderer()
+		if _, err := pr.Render(&cancelAfter{Context: context.Background(), n: 1}, "renderer-0", nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("并行 %v: 渲染中取消应返回 context.Canceled, 实际 %v", parallel, err)
+		}
+	}
+
+	// 留白可以覆盖为0
+	padded := NewPixelNebula().WithPadding(8).Renderer()
+	zero := 0
+	unpadded, _ := NewPixelNebula().Renderer().Render(context.Background(), "renderer-0", nil)
+	if got, _ := padded.Render(context.Background(), "renderer-0", &RenderOptions{Padding: &zero}); got != unpadded {
+		t.Errorf("留白应能覆盖为0")
+	}
+
+	// 自定义动画需要能深拷贝，否则不与原实例共享而是返回错误
+	if _, err := NewPixelNebula().WithAnimation(customAnimation{}).Renderer().Render(context.Background(), "renderer-0", nil); !errors.Is(err, errors.ErrInvalidAnimation) {
+		t.Errorf("无法深拷贝的自定义动画应返回 ErrInvalidAnimation, 实际 %v", err)
+	}
+	if _, err := NewRenderer(WithAnimations(customAnimation{})); !errors.Is(err, errors.ErrInvalidAnimation) {
+		t.Errorf("NewRenderer 应返回 ErrInvalidAnimation, 实际 %v", err)
+	}
+	custom := &cloneableAnimation{to: "0.5"}
+	cr := NewPixelNebula().WithAnimation(custom).Renderer()
+	before, err := cr.Render(context.Background(), "renderer-0", nil)
+	if err != nil || !strings.Contains(before, `to="0.5"`) {
+		t.Fatalf("实现了 Cloner 的自定义动画应能渲染: %v", err)
+	}
+	custom.to = "0.1"
+	if after, _ := cr.Render(context.Background(), "renderer-0", nil); after != before {
+		t.Errorf("创建渲染器后修改自定义动画不应影响渲染结果")
+	}
+	if _, err := r.Render(context.Background(), "", nil); !errors.Is(err, errors.ErrAvatarIDRequired) {
+		t.Errorf("空ID应返回 ErrAvatarIDRequired, 实际 %v", err)
+	}
+}
+
+func TestBuilderAnimations(t *testing.T) {
+	pn := NewPixelNebula().WithDefaultCache().WithRotateAnimation("env", 0, 360, 4, -1)
+	// 缓存中的SVG经过压缩，先写入缓存再比较
+	pn.Generate("alice", false).ToSVG()
+	plain, err := pn.Generate("alice", false).ToSVG()
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+
+	blink, err := pn.Generate("alice", false).SetBlinkAnimation("eyes", 0.2, 1, 2, 2, -1).SetAnimationMode(animation.ModeCSS).ToSVG()
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	if strings.Count(blink, "@keyframes") < 2 {
+		t.Errorf("构建器的动画应与实例的动画合并")
+	}
+
+	// 构建器的动画和设置不应影响之后的生成
+	if again, _ := pn.Generate("alice", false).ToSVG(); again != plain {
+		t.Errorf("构建器的动画不应影响之后的生成")
+	}
+	if n := len(pn.AnimManager.GetAnimations()); n != 1 || pn.AnimManager.Mode() != animation.ModeSMIL {
+		t.Errorf("实例的动画管理器不应被修改, 动画数 %d, 模式 %q", n, pn.AnimManager.Mode())
+	}
+	sized, err := pn.Generate("alice", false).SetSize(64, 32).SetParallelRender(true).SetConcurrencyPool(3).ToSVG()
+	if err != nil || !strings.Contains(sized, `width="64" height="32"`) {
+		t.Fatalf("构建器设置尺寸失败: %v", err)
+	}
+	if pn.Width != ArtworkSize || pn.Height != ArtworkSize || pn.ImgData != nil || pn.Options.ParallelRender || pn.Options.ConcurrencyPool == 3 {
+		t.Errorf("构建器的尺寸和并发设置不应写入实例")
+	}
+	if again, _ := pn.Generate("alice", false).ToSVG(); again != plain {
+		t.Errorf("构建器的尺寸不应影响之后的生成")
+	}
+
+	m := animation.NewAnimationManager()
+	rotate := animation.NewRotateAnimation("env", 0, 360, 4, -1)
+	m.AddAnimation(rotate)
+	m.AddAnimation(animation.NewGradientAnimation("clo", []string{"#ff0000", "#00ff00"}, 2, -1, true))
+	c := m.Clone()
+	c.GetAnimations()[1].(*animation.GradientAnimation).Colors[0] = "#0000ff"
+	if m.GetAnimations()[1].(*animation.GradientAnimation).Colors[0] != "#ff0000" {
+		t.Errorf("Clone 应深拷贝动画")
+	}
+	if c.RemoveAnimation(rotate) {
+		t.Errorf("副本中不应包含原动画对象")
+	}
+	if !m.RemoveAnimation(rotate) || len(m.GetAnimations()) != 1 || m.RemoveAnimation(rotate) {
+		t.Errorf("RemoveAnimation 应移除一次且只移除指定的动画")
+	}
+	m.Clear()
+	if len(m.GetAnimations()) != 0 || m.Generate() != "" {
+		t.Errorf("Clear 后不应有动画")
+	}
+}
+
+func TestCacheKeyCoversInputs(t *testing.T) {
+	options := cache.DefaultCacheOptions
+	options.Compression.Enabled = false
+	cached := NewPixelNebula().WithCache(options)
+	plain := NewPixelNebula()
+
+	check := func(step string, build func(pn *PixelNebula) *SVGBuilder) {
+		t.Helper()
+		want, err := build(plain).ToSVG()
+		if err != nil {
+			t.Fatalf("%s: 生成失败: %v", step, err)
+		}
+		for i := 0; i < 2; i++ {
+			if got, _ := build(cached).ToSVG(); got != want {
+				t.Errorf("%s: 缓存返回了过期的结果", step)
+			}
+		}
+	}
+	// ID中包含下划线，检查按缓存键删除时的解析
+	generate := func(pn *PixelNebula) *SVGBuilder { return pn.Generate("cache_key", false) }
+
+	check("默认", generate)
+	check("尺寸", func(pn *PixelNebula) *SVGBuilder { return generate(pn).SetSize(64, 64) })
+	check("背景", func(pn *PixelNebula) *SVGBuilder {
+		return generate(pn).SetBackground(BackgroundLinearGradient, "#336699").SetMetadata(MetadataJSON)
+	})
+	check("部分颜色", func(pn *PixelNebula) *SVGBuilder {
+		return generate(pn).SetPartColors(style.TypeHead, theme.ColorScheme{"#123456"})
+	})
+	check("构建器动画", func(pn *PixelNebula) *SVGBuilder {
+		return generate(pn).SetRotateAnimation("env", 0, 360, 4, -1)
+	})
+
+	for _, pn := range []*PixelNebula{cached, plain} {
+		pn.WithBlinkAnimation("eyes", 0.2, 1, 2, 2, -1)
+	}
+	check("实例动画", generate)
+	// 修改主题集的颜色
+	for _, pn := range []*PixelNebula{cached, plain} {
+		themes := make([]theme.Theme, pn.ThemeManager.StyleCount())
+		for i := range themes {
+			for j := 0; j < pn.ThemeManager.ThemeCount(i); j++ {
+				src, _ := pn.ThemeManager.GetTheme(i, j)
+				part := make(theme.ThemePart, len(src))
+				for name, colors := range src {
+					part[name] = make(theme.ColorScheme, len(colors))
+					for k := range colors {
+						part[name][k] = fmt.Sprintf("#%02x%02x%02x", i, j, k)
+					}
+				}
+				themes[i] = append(themes[i], part)
+			}
+		}
+		pn.WithCustomizeTheme(themes)
+	}
+	check("主题集", generate)
+
+	// 缓存中保存的是画布，同一ID交替使用不同的尺寸和背景时，每次都应得到对应的结果
+	for i := 0; i < 2; i++ {
+		for _, size := range []int{64, 128} {
+			for _, bg := range []BackgroundMode{BackgroundTheme, BackgroundRadialGradient} {
+				check(fmt.Sprintf("尺寸 %d 背景 %q", size, bg), func(pn *PixelNebula) *SVGBuilder {
+					return generate(pn).SetSize(size, size).SetBackground(bg, "#336699")
+				})
+			}
+		}
+	}
+
+	// 只改变尺寸或背景时共用一个缓存项
+	items := cached.GetCacheItems()
+	if len(items) != 5 {
+		t.Errorf("每组影响画布的输入应各有一个缓存项, 实际 %d", len(items))
+	}
+	if !cached.DeleteCacheItem(items[0].Key.String()) || len(cached.GetCacheItems()) != 4 {
+		t.Errorf("应能按缓存键删除缓存项")
+	}
+	// 不含摘要的旧格式删除该ID的所有缓存项
+	legacy := items[1].Key
+	legacy.Digest = ""
+	if !cached.DeleteCacheItem(legacy.String()) || len(cached.GetCacheItems()) != 0 {
+		t.Errorf("旧格式的缓存键应删除该ID的所有缓存项, 剩余 %d", len(cached.GetCacheItems()))
+	}
+
+	// 选择算法的缓存键与原来的不同，即使不清空缓存也不会命中旧的缓存项
+	cached.Selection, plain.Selection = SelectionV2, SelectionV2
+	check("选择算法", generate)
+}
@@ -0,0 +1,197 @@
+package pixelnebula
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"log/slog"
+
+	"github.com/landaiqing/go-pixelnebula/animation"
+	"github.com/landaiqing/go-pixelnebula/errors"
+	"github.com/landaiqing/go-pixelnebula/style"
+	"github.com/landaiqing/go-pixelnebula/theme"
+)
+
+// Renderer 由配置快照创建的不可变渲染器，可以被任意数量的 goroutine 同时使用
+// 创建后修改原 PixelNebula 实例不会影响渲染器，动画也被深拷贝
+// 自定义动画需要实现 animation.Cloner，否则 Render 返回 ErrInvalidAnimation；渲染器不使用缓存
+type Renderer struct {
+	svgEnd     string
+	themes     *theme.Manager
+	styles     *style.Manager
+	animations *animation.Manager // 只在渲染时读取
+	hashFunc   func() hash.Hash
+	namespaced bool
+	selection  SelectionVersion
+	partStyles map[style.ShapeType][]int
+	styleIndex int
+	themeIndex int
+	parallel   bool
+	pool       int
+	width      int
+	height     int
+	padding    int
+	aspect     string
+	metadata   MetadataFormat
+	logger     *slog.Logger
+	err        error // 原实例记录的配置错误
+}
+
+// RenderOptions 单次渲染的选项，零值表示使用渲染器的设置
+type RenderOptions struct {
+	SansEnv         bool                                  // 是否不包含背景形状
+	Width           int                                   // 输出宽度（像素）
+	Height          int                                   // 输出高度（像素）
+	Padding         *int                                  // 四周留白（像素），nil 时使用渲染器的设置
+	AspectRatio     string                                // preserveAspectRatio
+	Background      BackgroundMode                        // 背景模式
+	BackgroundColor string                                // 背景颜色或渐变、图案的基础色
+	Mask            MaskShape                             // 裁剪形状
+	IDPrefix        string                                // 元素ID前缀
+	Accessible      bool                                  // 是否输出无障碍信息
+	Title           string                                // 无障碍标题，设置后同时输出无障碍信息
+	Description     string                                // 无障碍描述，设置后同时输出无障碍信息
+	Metadata        MetadataFormat                        // 嵌入的元数据格式
+	Parts           map[style.ShapeType][2]int            // 固定风格和主题的部分，主题为-1时沿用按ID选择的主题
+	PartColors      map[style.ShapeType]theme.ColorScheme // 替换部分的颜色
+	Animations      []animation.Animation                 // 追加到渲染器动画之后的动画
+}
+
+// Renderer 创建当前配置的快照渲染器
+// 实例记录了配置错误或使用了无法深拷贝的自定义动画时，Render 返回该错误
+func (pn *PixelNebula) Renderer() *Renderer {
+	var partStyles map[style.ShapeType][]int
+	if len(pn.PartStyles) > 0 {
+		partStyles = make(map[style.ShapeType][]int, len(pn.PartStyles))
+		for part, indexes := range pn.PartStyles {
+			partStyles[part] = append([]int(nil), indexes...)
+		}
+	}
+
+	// 无法深拷贝的自定义动画会与原实例共享，记录错误而不是共享
+	err := pn.err
+	for _, anim := range pn.AnimManager.GetAnimations() {
+		if !animation.Cloneable(anim) {
+			err = errors.Join(err, fmt.Errorf("%w: custom animation %T does not implement animation.Cloner", errors.ErrInvalidAnimation, anim))
+		}
+	}
+
+	return &Renderer{
+		svgEnd:     pn.SvgEnd,
+		themes:     pn.ThemeManager.Clone(),
+		styles:     pn.StyleManager.Clone(),
+		animations: pn.AnimManager.Clone(),
+		hashFunc:   pn.HashFunc,
+		namespaced: pn.Namespaced,
+		selection:  pn.Selection,
+		partStyles: partStyles,
+		styleIndex: pn.Options.StyleIndex,
+		themeIndex: pn.Options.ThemeIndex,
+		parallel:   pn.Options.ParallelRender,
+		pool:       pn.Options.ConcurrencyPool,
+		width:      pn.Width,
+		height:     pn.Height,
+		padding:    pn.Padding,
+		aspect:     pn.AspectRatio,
+		metadata:   pn.Metadata,
+		logger:     pn.Logger,
+		err:        err,
+	}
+}
+
+// NewRenderer 使用选项创建渲染器，选项与 New 相同
+func NewRenderer(opts ...Option) (*Renderer, error) {
+	pn, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	r := pn.Renderer()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r, nil
+}
+
+// instance 为一次渲染创建独立的实例，只共享渲染时只读的管理器
+func (r *Renderer) instance() *PixelNebula {
+	pn := &PixelNebula{
+		SvgEnd:       r.svgEnd,
+		ThemeManager: r.themes,
+		StyleManager: r.styles,
+		AnimManager:  r.animations,
+		HashFunc:     r.hashFunc,
+		Namespaced:   r.namespaced,
+		Selection:    r.selection,
+		PartStyles:   r.partStyles,
+		Options: &PNOptions{
+			ThemeIndex:      r.themeIndex,
+			StyleIndex:      r.styleIndex,
+			ParallelRender:  r.parallel,
+			ConcurrencyPool: r.pool,
+		},
+		Width:       r.width,
+		Height:      r.height,
+		Padding:     r.padding,
+		AspectRatio: r.aspect,
+		Metadata:    r.metadata,
+		Logger:      r.logger,
+		err:         r.err,
+	}
+	pn.Hasher = pn.newHasher()
+	return pn
+}
+
+// Render 生成头像的SVG，opts 为 nil 时使用渲染器的设置
+func (r *Renderer) Render(ctx context.Context, id string, opts *RenderOptions) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if opts == nil {
+		opts = &RenderOptions{}
+	}
+
+	pn := r.instance()
+	sb := pn.Generate(id, opts.SansEnv)
+	sb.ctx = ctx
+	if opts.Width > 0 || opts.Height > 0 {
+		width, height := opts.Width, opts.Height
+		if width <= 0 {
+			width = r.width
+		}
+		if height <= 0 {
+			height = r.height
+		}
+		sb.SetSize(width, height)
+	}
+	if opts.Padding != nil {
+		sb.SetPadding(*opts.Padding)
+	}
+	if opts.AspectRatio != "" {
+		sb.SetPreserveAspectRatio(opts.AspectRatio)
+	}
+	if opts.Metadata != MetadataNone {
+		sb.SetMetadata(opts.Metadata)
+	}
+	for part, key := range opts.Parts {
+		sb.SetPart(part, key[0], key[1])
+	}
+	for part, colors := range opts.PartColors {
+		sb.SetPartColors(part, colors)
+	}
+	for _, anim := range opts.Animations {
+		sb.SetAnimation(anim)
+	}
+	if opts.Title != "" {
+		sb.SetTitle(opts.Title)
+	}
+	if opts.Description != "" {
+		sb.SetDescription(opts.Description)
+	}
+	if opts.Accessible {
+		sb.SetAccessible(true)
+	}
+	return sb.SetBackground(opts.Background, opts.BackgroundColor).
+		SetMask(opts.Mask).
+		SetIDPrefix(opts.IDPrefix).
+		ToSVG()
+}
@@ -0,0 +1,285 @@
+package pixelnebula
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+	"github.com/landaiqing/go-pixelnebula/style"
+)
+
+// SelectionVersion 由头像ID选择各部分风格和主题的算法版本
+type SelectionVersion int
+
+// 预定义选择算法版本常量
+const (
+	// SelectionV1 对哈希数字取模（默认），算法已冻结，同一ID的头像保持不变
+	// 增加风格或主题会改变几乎所有ID的头像
+	SelectionV1 SelectionVersion = 1
+	// SelectionV2 按风格名称和主题颜色做会合哈希（rendezvous hashing）
+	// 增加一个风格或主题只会让约 1/(n+1) 的ID改用新的风格或主题，其余ID的头像保持不变
+	SelectionV2 SelectionVersion = 2
+	// SelectionV3 为每个部分的风格和主题各取哈希摘要中独立的16位，分布接近均匀
+	// 需要至少24字节的摘要
+	SelectionV3 SelectionVersion = 3
+)
+
+// bytesPerChoice SelectionV3 每次选择使用的摘要字节数
+const bytesPerChoice = 2
+
+// validSelection 判断选择算法版本是否有效，0 等同于 SelectionV1
+func validSelection(v SelectionVersion) bool {
+	return v >= 0 && v <= SelectionV3
+}
+
+// WithSelectionVersion 设置选择算法版本
+// 更换版本会改变头像，已有的缓存会被清空；版本无效时记录错误，见 Err
+func (pn *PixelNebula) WithSelectionVersion(version SelectionVersion) *PixelNebula {
+	if !validSelection(version) {
+		return pn.setErr(fmt.Errorf("%w: unknown selection version %d", errors.ErrInvalidOption, version))
+	}
+	pn.Selection = version
+	if pn.Cache != nil {
+		pn.Cache.Clear()
+	}
+	return pn
+}
+
+// WithPartStyles 限制某个部分只从指定的风格中选择，不传风格表示取消限制
+// 设置后即使用 WithStyle 固定了风格，该部分仍会在列表中按ID选择，从而组合不同风格的部分
+// 风格不存在或缺少该部分的形状时记录错误且不修改限制，见 Err
+func (pn *PixelNebula) WithPartStyles(part style.ShapeType, styles ...style.StyleType) *PixelNebula {
+	indexes, err := pn.resolvePartStyles(part, styles)
+	if err != nil {
+		return pn.setErr(err)
+	}
+	if len(indexes) == 0 {
+		delete(pn.PartStyles, part)
+	} else {
+		if pn.PartStyles == nil {
+			pn.PartStyles = make(map[style.ShapeType][]int)
+		}
+		pn.PartStyles[part] = indexes
+	}
+	if pn.Cache != nil {
+		pn.Cache.Clear()
+	}
+	return pn
+}
+
+// resolvePartStyles 将部分允许使用的风格名称转换为风格索引
+func (pn *PixelNebula) resolvePartStyles(part style.ShapeType, styles []style.StyleType) ([]int, error) {
+	known := false
+	for _, p := range partOrder {
+		known = known || p == part
+	}
+	if !known {
+		return nil, fmt.Errorf("%w: %q", errors.ErrInvalidShapeType, part)
+	}
+
+	indexes := make([]int, 0, len(styles))
+	for _, s := range styles {
+		index, err := pn.StyleManager.GetStyleIndex(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, s)
+		}
+		if _, err := pn.StyleManager.GetShape(index, part); err != nil {
+			return nil, err
+		}
+		if pn.ThemeManager.ThemeCount(index) == 0 {
+			return nil, &errors.IndexError{Kind: errors.IndexTheme, Got: 0, Max: -1, Style: index}
+		}
+		dup := false
+		for _, i := range indexes {
+			dup = dup || i == index
+		}
+		if !dup {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes, nil
+}
+
+// partKeys 计算头像各部分使用的风格和主题索引
+// 设置了允许风格列表的部分，使用会合哈希在列表中选择风格；opts 中固定的部分最后生效
+func (pn *PixelNebula) partKeys(id string, opts *PNOptions) (map[style.ShapeType][2]int, error) {
+	if id == "" {
+		return nil, errors.ErrAvatarIDRequired
+	}
+	keys, err := pn.selectKeys(id, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pn.PartStyles) > 0 {
+		// 固定主题时，若所选风格有该主题则沿用
+		theme := -1
+		if opts != nil && opts.StyleIndex >= 0 && opts.ThemeIndex >= 0 {
+			theme = opts.ThemeIndex
+		}
+		digest := pn.digest(id)
+		for part, allowed := range pn.PartStyles {
+			keys[part] = pn.rendezvousKey(digest, part, allowed, theme)
+		}
+	}
+	if opts != nil {
+		pn.applyPartOverrides(keys, opts.Parts)
+	}
+	return keys, nil
+}
+
+// selectKeys 按选择算法版本计算各部分的风格和主题索引
+func (pn *PixelNebula) selectKeys(id string, opts *PNOptions) (map[style.ShapeType][2]int, error) {
+	if pn.Selection == SelectionV2 || pn.Selection == SelectionV3 {
+		keys := make(map[style.ShapeType][2]int, len(partOrder))
+		if opts != nil && opts.StyleIndex >= 0 && opts.ThemeIndex >= 0 {
+			for _, part := range partOrder {
+				keys[part] = [2]int{opts.StyleIndex, opts.ThemeIndex}
+			}
+			return keys, nil
+		}
+		digest := pn.digest(id)
+		if pn.Selection == SelectionV3 && len(digest) < 2*bytesPerChoice*len(partOrder) {
+			return nil, errors.ErrInsufficientHash
+		}
+		for i, part := range partOrder {
+			if pn.Selection == SelectionV2 {
+				keys[part] = pn.rendezvousKey(digest, part, nil, -1)
+			} else {
+				keys[part] = pn.bitsKey(digest[2*bytesPerChoice*i:])
+			}
+		}
+		return keys, nil
+	}
+
+	hashStr, err := pn.hashDigits(id)
+	if err != nil {
+		return nil, err
+	}
+	return map[style.ShapeType][2]int{
+		style.TypeEnv:   pn.calcKey(hashStr[:2], opts),
+		style.TypeClo:   pn.calcKey(hashStr[2:4], opts),
+		style.TypeHead:  pn.calcKey(hashStr[4:6], opts),
+		style.TypeMouth: pn.calcKey(hashStr[6:8], opts),
+		style.TypeEyes:  pn.calcKey(hashStr[8:10], opts),
+		style.TypeTop:   pn.calcKey(hashStr[10:], opts),
+	}, nil
+}
+
+// digest 计算avatarId的完整哈希值
+func (pn *PixelNebula) digest(id string) []byte {
+	pn.Hasher.Reset()
+	pn.Hasher.Write([]byte(id))
+	return pn.Hasher.Sum(nil)
+}
+
+// styleKey 风格在会合哈希中的稳定标识，有名称时使用名称，否则使用索引
+func (pn *PixelNebula) styleKey(index int) string {
+	if name, err := pn.StyleManager.GetStyleName(index); err == nil {
+		return string(name)
+	}
+	return "#" + strconv.Itoa(index)
+}
+
+// rendezvousKey 使用会合哈希为一个部分选择风格和主题
+// 先在候选风格中选择权重最大的风格，再在该风格的主题中选择权重最大的主题
+// candidates 为 nil 时候选所有风格；theme 不小于0且所选风格有该主题时直接使用
+func (pn *PixelNebula) rendezvousKey(digest []byte, part style.ShapeType, candidates []int, theme int) [2]int {
+	styleCount := pn.StyleManager.StyleSetCount()
+	if n := pn.ThemeManager.StyleCount(); n < styleCount {
+		styleCount = n
+	}
+	if candidates != nil {
+		styleCount = len(candidates)
+	}
+
+	best, bestKey := -1, ""
+	var bestWeight uint64
+	for c := 0; c < styleCount; c++ {
+		i := c
+		if candidates != nil {
+			i = candidates[c]
+		}
+		if pn.ThemeManager.ThemeCount(i) == 0 {
+			continue
+		}
+		key := pn.styleKey(i)
+		if w := rendezvousWeight(digest, string(part), key); best < 0 || w > bestWeight {
+			best, bestKey, bestWeight = i, key, w
+		}
+	}
+	if best < 0 {
+		return [2]int{0, 0}
+	}
+
+	themeCount := pn.ThemeManager.ThemeCount(best)
+	if theme >= 0 && theme < themeCount {
+		return [2]int{best, theme}
+	}
+	theme = 0
+	for j := 0; j < themeCount; j++ {
+		if w := rendezvousWeight(digest, string(part), pn.themeKey(best, j, part, bestKey)); j == 0 || w > bestWeight {
+			theme, bestWeight = j, w
+		}
+	}
+	return [2]int{best, theme}
+}
+
+// themeKey 主题在会合哈希中的稳定标识，使用主题中该部分的颜色
+// 插入或删除其他主题不会改变已有主题的权重；该部分颜色相同的主题生成的部分相同，谁被选中都不影响头像
+func (pn *PixelNebula) themeKey(styleIndex, themeIndex int, part style.ShapeType, styleKey string) string {
+	colors, err := pn.ThemeManager.GetTheme(styleIndex, themeIndex)
+	if err != nil {
+		return styleKey + "/#" + strconv.Itoa(themeIndex)
+	}
+	return styleKey + "/" + strings.Join(colors[string(part)], ",")
+}
+
+// bitsKey 用摘要中独立的两段比特分别选择风格和主题
+func (pn *PixelNebula) bitsKey(bits []byte) [2]int {
+	styleCount := pn.StyleManager.StyleSetCount()
+	if n := pn.ThemeManager.StyleCount(); n < styleCount {
+		styleCount = n
+	}
+	styleIndex := scaleBits(bits[:bytesPerChoice], styleCount)
+	themeIndex := scaleBits(bits[bytesPerChoice:2*bytesPerChoice], pn.ThemeManager.ThemeCount(styleIndex))
+	return [2]int{styleIndex, themeIndex}
+}
+
+// scaleBits 将大端序的16位数按乘法映射到 [0, n)，避免取模集中在低位
+func scaleBits(b []byte, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	v := int(b[0])<<8 | int(b[1])
+	return v * n >> 16
+}
+
+// rendezvousWeight 计算ID哈希、部分和候选项组合的权重
+// 使用 FNV-1a 混合输入，再经 splitmix64 终结函数打散
+func rendezvousWeight(digest []byte, part, key string) uint64 {
+	const (
+		offset = 14695981039346656037
+		prime  = 1099511628211
+	)
+	h := uint64(offset)
+	for _, b := range digest {
+		h = (h ^ uint64(b)) * prime
+	}
+	h = (h ^ 0xff) * prime
+	for i := 0; i < len(part); i++ {
+		h = (h ^ uint64(part[i])) * prime
+	}
+	h = (h ^ 0xff) * prime
+	for i := 0; i < len(key); i++ {
+		h = (h ^ uint64(key[i])) * prime
+	}
+
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
@@ -0,0 +1,122 @@
+package pixelnebula
+
+import (
+	"math"
+	"strings"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+// ArtworkSize 内置形状绘制所在网格的边长
+const ArtworkSize = 231
+
+// artworkSVGStart 画布的SVG开始标签，输出前会根据尺寸替换
+const artworkSVGStart = "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 231 231\">"
+
+// layout 输出尺寸设置
+type layout struct {
+	width   int    // 输出宽度（像素）
+	height  int    // 输出高度（像素）
+	padding int    // 四周留白（像素）
+	aspect  string // preserveAspectRatio，为空时使用 xMidYMid meet
+}
+
+// validAlign preserveAspectRatio 支持的对齐方式
+var validAlign = map[string]bool{
+	"none":     true,
+	"xMinYMin": true, "xMidYMin": true, "xMaxYMin": true,
+	"xMinYMid": true, "xMidYMid": true, "xMaxYMid": true,
+	"xMinYMax": true, "xMidYMax": true, "xMaxYMax": true,
+}
+
+// parseAspectRatio 解析 preserveAspectRatio，返回对齐方式和是否裁剪
+func parseAspectRatio(value string) (align string, slice bool, err error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return "xMidYMid", false, nil
+	}
+	if len(fields) > 2 || !validAlign[fields[0]] {
+		return "", false, errors.ErrInvalidAspectRatio
+	}
+	if len(fields) == 2 {
+		switch fields[1] {
+		case "meet":
+		case "slice":
+			slice = true
+		default:
+			return "", false, errors.ErrInvalidAspectRatio
+		}
+	}
+	return fields[0], slice, nil
+}
+
+// rootTag 生成带有宽高和viewBox的SVG开始标签
+// viewBox 按留白和对齐方式扩展，使231网格的画布缩放到输出区域内
+func (l layout) rootTag() (string, error) {
+	align, slice, err := parseAspectRatio(l.aspect)
+	if err != nil {
+		return "", err
+	}
+	innerW, innerH := float64(l.width-2*l.padding), float64(l.height-2*l.padding)
+	if l.width <= 0 || l.height <= 0 || l.padding < 0 || innerW <= 0 || innerH <= 0 {
+		return "", errors.ErrInvalidSize
+	}
+
+	// 每个画布单位对应的像素数
+	sx, sy := innerW/ArtworkSize, innerH/ArtworkSize
+	if align != "none" {
+		s := math.Min(sx, sy)
+		if slice {
+			s = math.Max(sx, sy)
+		}
+		sx, sy = s, s
+	}
+
+	vw, vh := float64(l.width)/sx, float64(l.height)/sy
+	vx := viewBoxOffset(align, 1, vw, float64(l.padding)/sx)
+	vy := viewBoxOffset(align, 5, vh, float64(l.padding)/sy)
+
+	var sb strings.Builder
+	sb.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="`)
+	sb.WriteString(formatFloat(float64(l.width)))
+	sb.WriteString(`" height="`)
+	sb.WriteString(formatFloat(float64(l.height)))
+	sb.WriteString(`" viewBox="`)
+	sb.WriteString(formatFloat(vx) + " " + formatFloat(vy) + " " + formatFloat(vw) + " " + formatFloat(vh))
+	sb.WriteString(`"`)
+	if l.aspect != "" {
+		sb.WriteString(` preserveAspectRatio="`)
+		sb.WriteString(strings.Join(strings.Fields(l.aspect), " "))
+		sb.WriteString(`"`)
+	}
+	sb.WriteString(">")
+	return sb.String(), nil
+}
+
+// viewBoxOffset 计算viewBox在一个方向上的起点
+// pos 为对齐方式中该方向取值（Min/Mid/Max）的位置，pad 为留白对应的画布单位
+func viewBoxOffset(align string, pos int, size, pad float64) float64 {
+	if align == "none" {
+		return -pad
+	}
+	switch align[pos : pos+3] {
+	case "Min":
+		return -pad
+	case "Max":
+		return ArtworkSize + pad - size
+	default:
+		return -(size - ArtworkSize) / 2
+	}
+}
+
+// applyLayout 将画布的SVG开始标签替换为按尺寸设置生成的标签
+func applyLayout(svg string, l layout) (string, error) {
+	if !strings.HasPrefix(svg, artworkSVGStart) {
+		return "", errors.ErrInvalidSVG
+	}
+	root, err := l.rootTag()
+	if err != nil {
+		return "", err
+	}
+	return root + svg[len(artworkSVGStart):], nil
+}
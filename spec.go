@@ -0,0 +1,239 @@
+package pixelnebula
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/landaiqing/go-pixelnebula/animation"
+	"github.com/landaiqing/go-pixelnebula/errors"
+	"github.com/landaiqing/go-pixelnebula/style"
+	"github.com/landaiqing/go-pixelnebula/theme"
+)
+
+// SpecVersion AvatarSpec 的格式版本
+const SpecVersion = 1
+
+// specID RenderSpec 生成画布时使用的占位ID，所有部分都已固定，不影响输出
+const specID = "avatar-spec"
+
+// AvatarSpec 头像的完整描述，可以序列化为JSON保存
+// 描述中记录了每个部分实际使用的风格和颜色，RenderSpec 不依赖哈希算法和风格列表的顺序，
+// 因此更换哈希函数、选择算法或增加风格后仍能生成相同的SVG
+// 描述中只记录形状的摘要，风格的形状被修改后 RenderSpec 返回错误而不是生成不同的头像
+// 描述中不包含头像ID，开启无障碍信息且未设置标题时，默认标题中的ID除外
+type AvatarSpec struct {
+	Version         int              `json:"version"`                       // 格式版本
+	Selection       SelectionVersion `json:"selection"`                     // 生成描述时使用的选择算法版本，仅供参考
+	Parts           []PartSpec       `json:"parts"`                         // 各部分的风格和颜色
+	SansEnv         bool             `json:"sansEnv,omitempty"`             // 是否不包含背景形状
+	Width           int              `json:"width"`                         // 输出宽度（像素）
+	Height          int              `json:"height"`                        // 输出高度（像素）
+	Padding         int              `json:"padding,omitempty"`             // 四周留白（像素）
+	AspectRatio     string           `json:"preserveAspectRatio,omitempty"` // preserveAspectRatio
+	Background      BackgroundMode   `json:"background,omitempty"`          // 背景模式
+	BackgroundColor string           `json:"backgroundColor,omitempty"`     // 背景颜色或基础色
+	Mask            MaskShape        `json:"mask,omitempty"`                // 裁剪形状
+	IDPrefix        string           `json:"idPrefix,omitempty"`            // 元素ID前缀
+	Accessible      bool             `json:"accessible,omitempty"`          // 是否输出无障碍信息
+	Title           string           `json:"title,omitempty"`               // 实际输出的无障碍标题
+	Description     string           `json:"description,omitempty"`         // 实际输出的无障碍描述
+	Metadata        MetadataFormat   `json:"metadata,omitempty"`            // 嵌入的元数据格式
+	AnimationMode   animation.Mode   `json:"animationMode,omitempty"`       // 动画输出模式
+	ReducedMotion   bool             `json:"reducedMotion,omitempty"`       // 是否响应 prefers-reduced-motion
+	Animations      []animation.Spec `json:"animations,omitempty"`          // 动画
+}
+
+// PartSpec 头像一个部分的描述
+type PartSpec struct {
+	Part       style.ShapeType   `json:"part"`            // 部分
+	Style      style.StyleType   `json:"style,omitempty"` // 风格名称，没有名称的自定义风格为空
+	StyleIndex int               `json:"styleIndex"`      // 风格索引，风格名称为空时使用
+	Theme      int               `json:"theme"`           // 主题索引，仅供参考，-1 表示颜色与所有主题都不一致
+	Colors     theme.ColorScheme `json:"colors"`          // 实际使用的颜色
+	Shape      string            `json:"shape,omitempty"` // 形状的SHA-256摘要（前8字节），为空时不校验
+}
+
+// shapeDigest 计算形状的摘要，用于检查描述中的风格是否被修改
+func shapeDigest(shape string) string {
+	sum := sha256.Sum256([]byte(shape))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Describe 生成头像的完整描述
+func (pn *PixelNebula) Describe(id string) (*AvatarSpec, error) {
+	return pn.Generate(id, false).Describe()
+}
+
+// Describe 按当前设置生成头像的完整描述
+// 使用了无法序列化的自定义动画时返回错误
+func (sb *SVGBuilder) Describe() (*AvatarSpec, error) {
+	if sb.hasError != nil {
+		return nil, sb.hasError
+	}
+	opts := sb.options()
+	keys, err := sb.pn.partKeys(sb.id, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	anims := sb.animManager()
+	selection := sb.pn.Selection
+	if selection == 0 {
+		selection = SelectionV1
+	}
+	spec := &AvatarSpec{
+		Version:         SpecVersion,
+		Selection:       selection,
+		Parts:           make([]PartSpec, 0, len(partOrder)),
+		SansEnv:         sb.sansEnv,
+		Width:           sb.width,
+		Height:          sb.height,
+		Padding:         sb.padding,
+		AspectRatio:     sb.aspect,
+		Background:      sb.background,
+		BackgroundColor: sb.bgColor,
+		Mask:            sb.mask,
+		IDPrefix:        sb.idPrefix,
+		Metadata:        sb.metadata,
+		AnimationMode:   anims.Mode(),
+		ReducedMotion:   anims.ReducedMotion(),
+	}
+	if sb.accessible {
+		if spec.Title, spec.Description, err = sb.accessibleText(); err != nil {
+			return nil, err
+		}
+		spec.Accessible = true
+	}
+
+	for _, part := range partOrder {
+		key := keys[part]
+		themePart, err := sb.pn.ThemeManager.GetTheme(key[0], key[1])
+		if err != nil {
+			return nil, err
+		}
+		colors, ok := themePart[string(part)]
+		if !ok {
+			return nil, errors.ErrInvalidColor
+		}
+		shape, err := sb.pn.StyleManager.GetShape(key[0], part)
+		if err != nil {
+			return nil, err
+		}
+		name, _ := sb.pn.StyleManager.GetStyleName(key[0])
+		spec.Parts = append(spec.Parts, PartSpec{
+			Part:       part,
+			Style:      name,
+			StyleIndex: key[0],
+			Theme:      key[1],
+			Colors:     mergeColors(colors, opts.PartColors[part]),
+			Shape:      shapeDigest(shape),
+		})
+	}
+
+	for _, anim := range anims.GetAnimations() {
+		a, err := animation.ToSpec(anim)
+		if err != nil {
+			return nil, err
+		}
+		spec.Animations = append(spec.Animations, a)
+	}
+	return spec, nil
+}
+
+// RenderSpec 按描述生成SVG
+// 风格按名称查找，没有名称时使用索引；描述中的动画代替实例的动画
+// 形状与描述中的摘要不一致时返回 ErrInvalidSpec
+func (pn *PixelNebula) RenderSpec(spec *AvatarSpec) (string, error) {
+	if spec == nil {
+		return "", fmt.Errorf("%w: spec is nil", errors.ErrInvalidSpec)
+	}
+	if spec.Version != SpecVersion {
+		return "", fmt.Errorf("%w: unsupported version %d", errors.ErrInvalidSpec, spec.Version)
+	}
+
+	parts := make(map[style.ShapeType][2]int, len(spec.Parts))
+	colors := make(map[style.ShapeType]theme.ColorScheme, len(spec.Parts))
+	for _, p := range spec.Parts {
+		if !isPart(p.Part) {
+			return "", fmt.Errorf("%w: %q", errors.ErrInvalidShapeType, p.Part)
+		}
+		index := p.StyleIndex
+		if p.Style != "" {
+			i, err := pn.StyleManager.GetStyleIndex(p.Style)
+			if err != nil {
+				return "", fmt.Errorf("%w: %q", err, p.Style)
+			}
+			index = i
+		}
+		shape, err := pn.StyleManager.GetShape(index, p.Part)
+		if err != nil {
+			return "", err
+		}
+		if p.Shape != "" && p.Shape != shapeDigest(shape) {
+			return "", fmt.Errorf("%w: shape of part %q in style %d has changed", errors.ErrInvalidSpec, p.Part, index)
+		}
+		// 颜色已经完整记录，主题只需存在即可
+		themeIndex := p.Theme
+		if count := pn.ThemeManager.ThemeCount(index); count == 0 {
+			return "", &errors.IndexError{Kind: errors.IndexTheme, Got: themeIndex, Max: -1, Style: index}
+		} else if themeIndex < 0 || themeIndex >= count {
+			themeIndex = 0
+		}
+		parts[p.Part] = [2]int{index, themeIndex}
+		colors[p.Part] = p.Colors
+	}
+	for _, part := range partOrder {
+		if _, ok := parts[part]; !ok && (part != style.TypeEnv || !spec.SansEnv) {
+			return "", fmt.Errorf("%w: missing part %q", errors.ErrInvalidSpec, part)
+		}
+	}
+
+	// 使用独立的实例生成，描述中的动画不会影响当前实例
+	r := &PixelNebula{
+		SvgEnd:       pn.SvgEnd,
+		ThemeManager: pn.ThemeManager,
+		StyleManager: pn.StyleManager,
+		AnimManager:  animation.NewAnimationManager(),
+		Hasher:       sha256.New(),
+		Options:      &PNOptions{ThemeIndex: -1, StyleIndex: -1, ConcurrencyPool: 1},
+		Width:        spec.Width,
+		Height:       spec.Height,
+		Logger:       pn.Logger,
+	}
+	if spec.Selection > 0 && validSelection(spec.Selection) {
+		// 只影响嵌入的元数据中记录的版本
+		r.Selection = spec.Selection
+	}
+	for _, a := range spec.Animations {
+		anim, err := animation.FromSpec(a)
+		if err != nil {
+			return "", err
+		}
+		r.AnimManager.AddAnimation(anim)
+	}
+	if spec.AnimationMode != "" {
+		r.AnimManager.SetMode(spec.AnimationMode)
+	}
+	r.AnimManager.SetReducedMotion(spec.ReducedMotion)
+
+	sb := r.Generate(specID, spec.SansEnv).
+		SetPadding(spec.Padding).
+		SetPreserveAspectRatio(spec.AspectRatio).
+		SetBackground(spec.Background, spec.BackgroundColor).
+		SetMask(spec.Mask).
+		SetMetadata(spec.Metadata)
+	if spec.IDPrefix != "" {
+		sb = sb.SetIDPrefix(spec.IDPrefix)
+	}
+	if spec.Accessible {
+		title := spec.Title
+		if title == "" {
+			// 描述中没有头像ID，不使用包含ID的默认标题
+			title = "Avatar"
+		}
+		sb = sb.SetTitle(title).SetDescription(spec.Description)
+	}
+	sb.parts, sb.partColors = parts, colors
+	return sb.ToSVG()
+}
@@ -188,33 +188,9 @@ var defaultStyleSet = map[StyleType]StyleSet{
 
 // initShapes 初始化形状数据
 func (m *Manager) initShapes() {
-	for _, style := range []StyleType{
-		RoboStyle,
-		GirlStyle,
-		BlondeStyle,
-		GuyStyle,
-		CountryStyle,
-		GeeknotStyle,
-		AsianStyle,
-		PunkStyle,
-		AfrohairStyle,
-		NormieFemaleStyle,
-		OlderStyle,
-		FirehairStyle,
-		BlondStyle,
-		AteamStyle,
-		RastaStyle,
-		MetaStyle,
-		SquareStyle,
-		NeonStyle,       // 霓虹风格
-		PixelStyle,      // 像素风格
-		WatercolorStyle, // 水彩风格
-		MechStyle,       // 机械风格
-		CosmicStyle,     // 宇宙风格
-		GhostStyle,      // 幽灵风格
-	} {
+	for _, style := range builtinStyles {
 		if styleSet, exists := defaultStyleSet[style]; exists {
-			m.AddStyleSet(styleSet)
+			m.AddNamedStyleSet(style, styleSet)
 		}
 	}
 }
@@ -39,12 +39,40 @@ const (
 // StyleType 表示风格类型
 type StyleType string
 
+// builtinStyles 内置风格，顺序即风格索引
+var builtinStyles = []StyleType{
+	RoboStyle,
+	GirlStyle,
+	BlondeStyle,
+	GuyStyle,
+	CountryStyle,
+	GeeknotStyle,
+	AsianStyle,
+	PunkStyle,
+	AfrohairStyle,
+	NormieFemaleStyle,
+	OlderStyle,
+	FirehairStyle,
+	BlondStyle,
+	AteamStyle,
+	RastaStyle,
+	MetaStyle,
+	SquareStyle,
+	NeonStyle,       // 霓虹风格
+	PixelStyle,      // 像素风格
+	WatercolorStyle, // 水彩风格
+	MechStyle,       // 机械风格
+	CosmicStyle,     // 宇宙风格
+	GhostStyle,      // 幽灵风格
+}
+
 // StyleSet 表示一组形状
 type StyleSet map[ShapeType]string
 
 // Manager 形状管理器，负责管理所有形状
 type Manager struct {
 	styleSets []StyleSet
+	names     []StyleType // 各形状集合的名称，未命名的为空
 }
 
 // NewShapeManager 创建一个新的形状管理器
@@ -57,13 +85,13 @@ func NewShapeManager() *Manager {
 // GetShape 获取指定索引和类型的形状
 func (m *Manager) GetShape(setIndex int, shapeType ShapeType) (string, error) {
 	if setIndex < 0 || setIndex >= len(m.styleSets) {
-		return "", errors.ErrInvalidShapeSetIndex
+		return "", &errors.IndexError{Kind: errors.IndexShapeSet, Got: setIndex, Max: len(m.styleSets) - 1, Style: -1}
 	}
 
 	shapeSet := m.styleSets[setIndex]
 	shape, ok := shapeSet[shapeType]
 	if !ok {
-		return "", errors.ErrInvalidShapeType
+		return "", &errors.ShapeError{Style: setIndex, Part: string(shapeType)}
 	}
 
 	return shape, nil
@@ -76,46 +104,55 @@ func (m *Manager) StyleSetCount() int {
 
 // AddStyleSet 添加一个新形状集合
 func (m *Manager) AddStyleSet(shapeSet StyleSet) int {
+	return m.AddNamedStyleSet("", shapeSet)
+}
+
+// AddNamedStyleSet 添加一个带名称的形状集合
+// 名称用于按名称查找风格，也是 SelectionV2 选择风格时的稳定标识
+func (m *Manager) AddNamedStyleSet(name StyleType, shapeSet StyleSet) int {
 	m.styleSets = append(m.styleSets, shapeSet)
+	m.names = append(m.names, name)
 	return len(m.styleSets) - 1
 }
 
+// Clone 深拷贝形状管理器，修改副本不会影响原管理器
+func (m *Manager) Clone() *Manager {
+	styleSets := make([]StyleSet, len(m.styleSets))
+	for i, set := range m.styleSets {
+		styleSets[i] = make(StyleSet, len(set))
+		for k, shape := range set {
+			styleSets[i][k] = shape
+		}
+	}
+	return &Manager{styleSets: styleSets, names: append([]StyleType(nil), m.names...)}
+}
+
 // CustomizeStyle 自定义风格
 func (m *Manager) CustomizeStyle(styleSets []StyleSet) {
 	m.styleSets = styleSets
+	m.names = make([]StyleType, len(styleSets))
 }
 
 // GetStyleIndex 根据风格类型获取对应的索引值
 func (m *Manager) GetStyleIndex(style StyleType) (int, error) {
-	// 遍历已初始化的风格列表获取索引
-	for i, s := range []StyleType{
-		RoboStyle,
-		GirlStyle,
-		BlondeStyle,
-		GuyStyle,
-		CountryStyle,
-		GeeknotStyle,
-		AsianStyle,
-		PunkStyle,
-		AfrohairStyle,
-		NormieFemaleStyle,
-		OlderStyle,
-		FirehairStyle,
-		BlondStyle,
-		AteamStyle,
-		RastaStyle,
-		MetaStyle,
-		SquareStyle,
-		NeonStyle,       // 霓虹风格
-		PixelStyle,      // 像素风格
-		WatercolorStyle, // 水彩风格
-		MechStyle,       // 机械风格
-		CosmicStyle,     // 宇宙风格
-		GhostStyle,      // 幽灵风格
-	} {
+	for i, name := range m.names {
+		if name != "" && name == style {
+			return i, nil
+		}
+	}
+	// 遍历内置风格列表获取索引
+	for i, s := range builtinStyles {
 		if s == style {
 			return i, nil
 		}
 	}
 	return -1, errors.ErrInvalidStyleName
 }
+
+// GetStyleName 根据索引获取风格的名称，通过 CustomizeStyle 或 AddStyleSet 添加的风格没有名称
+func (m *Manager) GetStyleName(index int) (StyleType, error) {
+	if index < 0 || index >= len(m.names) || m.names[index] == "" {
+		return "", &errors.IndexError{Kind: errors.IndexShapeSet, Got: index, Max: len(m.styleSets) - 1, Style: -1}
+	}
+	return m.names[index], nil
+}
@@ -0,0 +1,243 @@
+package pixelnebula
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/landaiqing/go-pixelnebula/errors"
+)
+
+// svgNode 轻量的SVG文档节点，用于对生成的SVG做结构化的后处理
+type svgNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []*svgNode
+	text     string // 文本节点内容，仅当 name 为空时有效
+}
+
+// parseSVGDOM 将SVG字符串解析为节点树，返回根元素
+func parseSVGDOM(svg string) (*svgNode, error) {
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	dec.Strict = false
+
+	var (
+		root  *svgNode
+		stack []*svgNode
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.ErrInvalidSVG
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &svgNode{name: t.Name.Local, attrs: make([]xml.Attr, 0, len(t.Attr))}
+			for _, a := range t.Attr {
+				n.attrs = append(n.attrs, xml.Attr{Name: xml.Name{Local: attrName(a.Name)}, Value: a.Value})
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, &svgNode{text: string(t)})
+			}
+		}
+	}
+
+	if root == nil || root.name != "svg" {
+		return nil, errors.ErrInvalidSVG
+	}
+	return root, nil
+}
+
+// attrName 还原属性的限定名
+func attrName(name xml.Name) string {
+	switch name.Space {
+	case "":
+		return name.Local
+	case "xmlns":
+		return "xmlns:" + name.Local
+	case "http://www.w3.org/1999/xlink", "xlink":
+		return "xlink:" + name.Local
+	default:
+		return name.Local
+	}
+}
+
+// isText 判断是否为文本节点
+func (n *svgNode) isText() bool {
+	return n.name == ""
+}
+
+// attr 获取属性值
+func (n *svgNode) attr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// setAttr 设置属性值，属性不存在时追加到末尾
+func (n *svgNode) setAttr(name, value string) {
+	for i, a := range n.attrs {
+		if a.Name.Local == name {
+			n.attrs[i].Value = value
+			return
+		}
+	}
+	n.attrs = append(n.attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// removeAttr 删除属性
+func (n *svgNode) removeAttr(name string) {
+	for i, a := range n.attrs {
+		if a.Name.Local == name {
+			n.attrs = append(n.attrs[:i], n.attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+// setStyleProp 在style属性中设置一个CSS声明，已存在时替换
+func (n *svgNode) setStyleProp(prop, value string) {
+	style, _ := n.attr("style")
+	decls := strings.Split(style, ";")
+	out := make([]string, 0, len(decls)+1)
+	replaced := false
+	for _, d := range decls {
+		kv := strings.SplitN(d, ":", 2)
+		if strings.TrimSpace(d) == "" {
+			continue
+		}
+		if len(kv) == 2 && strings.TrimSpace(kv[0]) == prop {
+			out = append(out, prop+":"+value)
+			replaced = true
+			continue
+		}
+		out = append(out, strings.TrimSpace(d))
+	}
+	if !replaced {
+		out = append(out, prop+":"+value)
+	}
+	n.setAttr("style", strings.Join(out, ";")+";")
+}
+
+// hasStyleProp 判断style属性中是否声明了指定的CSS属性
+func (n *svgNode) hasStyleProp(prop string) bool {
+	style, _ := n.attr("style")
+	for _, d := range strings.Split(style, ";") {
+		kv := strings.SplitN(d, ":", 2)
+		if len(kv) == 2 && strings.TrimSpace(kv[0]) == prop {
+			return true
+		}
+	}
+	return false
+}
+
+// innerText 获取节点下所有文本内容
+func (n *svgNode) innerText() string {
+	if n.isText() {
+		return n.text
+	}
+	var sb strings.Builder
+	for _, c := range n.children {
+		sb.WriteString(c.innerText())
+	}
+	return sb.String()
+}
+
+// find 深度优先查找第一个满足条件的节点，同时返回其父节点
+func (n *svgNode) find(match func(*svgNode) bool) (node, parent *svgNode) {
+	for _, c := range n.children {
+		if c.isText() {
+			continue
+		}
+		if match(c) {
+			return c, n
+		}
+		if found, p := c.find(match); found != nil {
+			return found, p
+		}
+	}
+	return nil, nil
+}
+
+// findByID 查找指定ID的元素及其父节点
+func (n *svgNode) findByID(id string) (node, parent *svgNode) {
+	return n.find(func(c *svgNode) bool {
+		v, ok := c.attr("id")
+		return ok && v == id
+	})
+}
+
+// removeChildren 递归删除满足条件的子元素
+func (n *svgNode) removeChildren(match func(*svgNode) bool) {
+	kept := n.children[:0]
+	for _, c := range n.children {
+		if !c.isText() && match(c) {
+			continue
+		}
+		c.removeChildren(match)
+		kept = append(kept, c)
+	}
+	n.children = kept
+}
+
+// String 将节点树序列化为SVG字符串
+func (n *svgNode) String() string {
+	var sb strings.Builder
+	n.write(&sb)
+	return sb.String()
+}
+
+// write 序列化节点
+func (n *svgNode) write(sb *strings.Builder) {
+	if n.isText() {
+		textEscaper.WriteString(sb, n.text)
+		return
+	}
+	sb.WriteByte('<')
+	sb.WriteString(n.name)
+	for _, a := range n.attrs {
+		sb.WriteByte(' ')
+		sb.WriteString(a.Name.Local)
+		sb.WriteString("=\"")
+		attrEscaper.WriteString(sb, a.Value)
+		sb.WriteByte('"')
+	}
+	if len(n.children) == 0 {
+		sb.WriteString("/>")
+		return
+	}
+	sb.WriteByte('>')
+	for _, c := range n.children {
+		c.write(sb)
+	}
+	sb.WriteString("</")
+	sb.WriteString(n.name)
+	sb.WriteByte('>')
+}
+
+var (
+	// textEscaper 文本节点转义
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	// attrEscaper 属性值转义
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;")
+)
@@ -28,12 +28,12 @@ func NewThemeManager() *Manager {
 // GetTheme 获取指定索引的主题
 func (m *Manager) GetTheme(themeIndex, partIndex int) (ThemePart, error) {
 	if themeIndex < 0 || themeIndex >= len(m.themes) {
-		return nil, errors.ErrInvalidTheme
+		return nil, &errors.IndexError{Kind: errors.IndexTheme, Got: themeIndex, Max: len(m.themes) - 1, Style: -1}
 	}
 
 	theme := m.themes[themeIndex]
 	if partIndex < 0 || partIndex >= len(theme) {
-		return nil, errors.ErrInvalidPart
+		return nil, &errors.IndexError{Kind: errors.IndexPart, Got: partIndex, Max: len(theme) - 1, Style: themeIndex}
 	}
 
 	return theme[partIndex], nil
@@ -58,6 +58,21 @@ func (m *Manager) AddTheme(theme Theme) int {
 	return len(m.themes) - 1
 }
 
+// Clone 深拷贝主题管理器，修改副本不会影响原管理器
+func (m *Manager) Clone() *Manager {
+	themes := make([]Theme, len(m.themes))
+	for i, theme := range m.themes {
+		themes[i] = make(Theme, len(theme))
+		for j, part := range theme {
+			themes[i][j] = make(ThemePart, len(part))
+			for k, colors := range part {
+				themes[i][j][k] = append(ColorScheme(nil), colors...)
+			}
+		}
+	}
+	return &Manager{themes: themes}
+}
+
 // CustomizeTheme 自定义主题
 func (m *Manager) CustomizeTheme(theme []Theme) {
 	m.themes = theme